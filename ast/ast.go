@@ -3,7 +3,10 @@ package ast
 import (
 	"bytes"
 	"dara/token"
+	"fmt"
+	"math/big"
 	"strings"
+	"unicode"
 )
 
 type Program struct {
@@ -18,6 +21,20 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -31,6 +48,17 @@ func (p *Program) String() string {
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first character of the node
+	End() token.Position // position immediately after the node
+}
+
+// after returns the position immediately after the single character at pos.
+func after(pos token.Position) token.Position {
+	if pos.IsValid() {
+		pos.Offset++
+		pos.Column++
+	}
+	return pos
 }
 
 /* --- Statement --- */
@@ -47,6 +75,8 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Expression.Pos() }
+func (es *ExpressionStatement) End() token.Position  { return es.Expression.End() }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -54,6 +84,21 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+// IncDecStatement increments or decrements a variable, array element or
+// object member: `x++` or `x[0]--`.
+type IncDecStatement struct {
+	Token  token.Token // the ++ or -- token
+	Target Expression
+}
+
+func (ids *IncDecStatement) statementNode()       {}
+func (ids *IncDecStatement) TokenLiteral() string { return ids.Token.Literal }
+func (ids *IncDecStatement) Pos() token.Position  { return ids.Target.Pos() }
+func (ids *IncDecStatement) End() token.Position  { return ids.Token.End }
+func (ids *IncDecStatement) String() string {
+	return ids.Target.String() + ids.TokenLiteral()
+}
+
 type CommentStatement struct {
 	Token token.Token
 	Value string
@@ -61,6 +106,8 @@ type CommentStatement struct {
 
 func (cs *CommentStatement) statementNode()       {}
 func (cs *CommentStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *CommentStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *CommentStatement) End() token.Position  { return cs.Token.End }
 func (cs *CommentStatement) String() string       { return cs.Value }
 
 type ReturnStatement struct {
@@ -70,6 +117,13 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -87,10 +141,13 @@ func (rs *ReturnStatement) String() string {
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	Rbrace     token.Position
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position  { return after(bs.Rbrace) }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -101,6 +158,22 @@ func (bs *BlockStatement) String() string {
 	return out.String()
 }
 
+// ConstStatement declares a constant: `const name = value`. Unlike a variable,
+// a constant can't be assigned to once declared.
+type ConstStatement struct {
+	Token token.Token
+	Name  *Identifier
+	Value Expression
+}
+
+func (cs *ConstStatement) statementNode()       {}
+func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ConstStatement) End() token.Position  { return cs.Value.End() }
+func (cs *ConstStatement) String() string {
+	return cs.TokenLiteral() + " " + cs.Name.String() + " = " + cs.Value.String() + ";"
+}
+
 type IfStatement struct {
 	Token       token.Token
 	Condition   Expression
@@ -110,6 +183,13 @@ type IfStatement struct {
 
 func (is *IfStatement) statementNode()       {}
 func (is *IfStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IfStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *IfStatement) End() token.Position {
+	if is.Alternative != nil {
+		return is.Alternative.End()
+	}
+	return is.Consequence.End()
+}
 func (is *IfStatement) String() string {
 	var out bytes.Buffer
 
@@ -128,6 +208,114 @@ func (is *IfStatement) String() string {
 	return out.String()
 }
 
+type ForStatement struct {
+	Token     token.Token
+	Init      Expression // nil unless the loop has a C-style header
+	Condition Expression // nil for a loop without a condition
+	Post      Statement  // nil unless the loop has a C-style header
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForStatement) End() token.Position  { return fs.Body.End() }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+
+	if fs.Init != nil || fs.Post != nil {
+		if fs.Init != nil {
+			// A declaration prints its own semicolon.
+			out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+		}
+		out.WriteString("; ")
+		if fs.Condition != nil {
+			out.WriteString(fs.Condition.String())
+		}
+		out.WriteString("; ")
+		if fs.Post != nil {
+			out.WriteString(fs.Post.String())
+		}
+		out.WriteString(" ")
+	} else if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+		out.WriteString(" ")
+	}
+
+	out.WriteString("{ ")
+	out.WriteString(fs.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type ForInStatement struct {
+	Token    token.Token
+	Key      *Identifier // nil when only the value is bound
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) Pos() token.Position  { return fs.Token.Pos }
+func (fs *ForInStatement) End() token.Position  { return fs.Body.End() }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+
+	if fs.Key != nil {
+		out.WriteString(fs.Key.String())
+		out.WriteString(", ")
+	}
+
+	out.WriteString(fs.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(" { ")
+	out.WriteString(fs.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+
+// BadStatement is a placeholder for a statement that could not be parsed. It
+// spans from its first token up to where the parser resynchronised.
+type BadStatement struct {
+	Token token.Token // the first token of the statement
+	To    token.Position
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BadStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BadStatement) End() token.Position  { return bs.To }
+func (bs *BadStatement) String() string       { return "<bad statement>;" }
+
 /* --- Expression --- */
 
 type Expression interface {
@@ -142,6 +330,8 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position  { return i.Token.End }
 func (i *Identifier) String() string       { return i.Value }
 
 type NumberLiteral struct {
@@ -151,8 +341,21 @@ type NumberLiteral struct {
 
 func (nl *NumberLiteral) expressionNode()      {}
 func (nl *NumberLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NumberLiteral) Pos() token.Position  { return nl.Token.Pos }
+func (nl *NumberLiteral) End() token.Position  { return nl.Token.End }
 func (nl *NumberLiteral) String() string       { return nl.Token.Literal }
 
+type IntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (il *IntegerLiteral) expressionNode()      {}
+func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
+func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -161,6 +364,8 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position  { return pe.Right.End() }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -181,6 +386,8 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return ie.Left.Pos() }
+func (ie *InfixExpression) End() token.Position  { return ie.Right.End() }
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -200,6 +407,8 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) End() token.Position  { return b.Token.End }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 type Nil struct {
@@ -208,6 +417,8 @@ type Nil struct {
 
 func (n *Nil) expressionNode()      {}
 func (n *Nil) TokenLiteral() string { return n.Token.Literal }
+func (n *Nil) Pos() token.Position  { return n.Token.Pos }
+func (n *Nil) End() token.Position  { return n.Token.End }
 func (n *Nil) String() string       { return n.Token.Literal }
 
 type StringLiteral struct {
@@ -217,17 +428,106 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
-func (sl *StringLiteral) String() string       { return sl.Value }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
+func (sl *StringLiteral) String() string       { return quote(sl.Value) }
+
+// InterpolatedString is a string with embedded expressions. Its parts
+// alternate between the StringLiterals around each `${ }` and the expressions
+// inside them, starting and ending with a StringLiteral.
+type InterpolatedString struct {
+	Token token.Token // the TEMPLATE_HEAD token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) Pos() token.Position  { return is.Token.Pos }
+func (is *InterpolatedString) End() token.Position  { return is.Parts[len(is.Parts)-1].End() }
+func (is *InterpolatedString) String() string {
+	var out strings.Builder
+
+	out.WriteByte('"')
+	for i, part := range is.Parts {
+		if i%2 == 0 {
+			out.WriteString(escape(part.(*StringLiteral).Value))
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	out.WriteByte('"')
+
+	return out.String()
+}
+
+// quote returns s as a double-quoted string literal, escaping it so that it
+// scans back to the same value.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+func escape(s string) string {
+	var out strings.Builder
+
+	for i, r := range s {
+		switch r {
+		case '"', '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case '$':
+			if strings.HasPrefix(s[i:], "${") {
+				out.WriteByte('\\')
+			}
+			out.WriteRune(r)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\r':
+			out.WriteString(`\r`)
+		default:
+			if unicode.IsPrint(r) {
+				out.WriteRune(r)
+			} else {
+				fmt.Fprintf(&out, `\u{%x}`, r)
+			}
+		}
+	}
+
+	return out.String()
+}
 
 type FunctionLiteral struct {
 	Token      token.Token
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       *BlockStatement
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Position  { return fl.Body.End() }
 func (fl *FunctionLiteral) String() string {
+	return functionString(fl.TokenLiteral(), fl)
+}
+
+// FunctionStatement declares a named function: `fn name(a, b) { }`. The
+// function is bound when the block it is declared in is entered, so it can be
+// called before the declaration.
+type FunctionStatement struct {
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Function.TokenLiteral() }
+func (fs *FunctionStatement) Pos() token.Position  { return fs.Function.Pos() }
+func (fs *FunctionStatement) End() token.Position  { return fs.Function.End() }
+func (fs *FunctionStatement) String() string {
+	return functionString(fs.TokenLiteral()+" "+fs.Name.String(), fs.Function)
+}
+
+func functionString(prefix string, fl *FunctionLiteral) string {
 	var out bytes.Buffer
 
 	var params []string
@@ -235,7 +535,7 @@ func (fl *FunctionLiteral) String() string {
 		params = append(params, p.String())
 	}
 
-	out.WriteString(fl.TokenLiteral())
+	out.WriteString(prefix)
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") { ")
@@ -245,14 +545,44 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// Parameter is a function parameter, with the value it takes when no
+// argument is passed for it. A rest parameter (`...rest`) collects any
+// arguments left over into an array.
+type Parameter struct {
+	Name    *Identifier
+	Default Expression // nil if an argument is required
+	Rest    bool
+}
+
+func (p *Parameter) TokenLiteral() string { return p.Name.TokenLiteral() }
+func (p *Parameter) Pos() token.Position  { return p.Name.Pos() }
+func (p *Parameter) End() token.Position {
+	if p.Default != nil {
+		return p.Default.End()
+	}
+	return p.Name.End()
+}
+func (p *Parameter) String() string {
+	if p.Rest {
+		return "..." + p.Name.String()
+	}
+	if p.Default != nil {
+		return p.Name.String() + " = " + p.Default.String()
+	}
+	return p.Name.String()
+}
+
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	Rparen    token.Position
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Function.Pos() }
+func (ce *CallExpression) End() token.Position  { return after(ce.Rparen) }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -277,6 +607,8 @@ type DeclareExpression struct {
 
 func (de *DeclareExpression) expressionNode()      {}
 func (de *DeclareExpression) TokenLiteral() string { return de.Token.Literal }
+func (de *DeclareExpression) Pos() token.Position  { return de.Name.Pos() }
+func (de *DeclareExpression) End() token.Position  { return de.Value.End() }
 func (de *DeclareExpression) String() string {
 	var out bytes.Buffer
 
@@ -292,18 +624,24 @@ func (de *DeclareExpression) String() string {
 	return out.String()
 }
 
+// AssignExpression is an assignment with `=`, or a compound assignment like
+// `+=` that applies Operator to the current value and Value. The target is an
+// Identifier, IndexExpression or MemberExpression.
 type AssignExpression struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token    token.Token
+	Target   Expression
+	Operator string // "+" for `+=` and so on, empty for `=`
+	Value    Expression
 }
 
 func (ae *AssignExpression) expressionNode()      {}
 func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return ae.Target.Pos() }
+func (ae *AssignExpression) End() token.Position  { return ae.Value.End() }
 func (ae *AssignExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ae.Name.String())
+	out.WriteString(ae.Target.String())
 	out.WriteString(" " + ae.TokenLiteral() + " ")
 	out.WriteString(ae.Value.String())
 
@@ -311,13 +649,16 @@ func (ae *AssignExpression) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Position
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return ie.Left.Pos() }
+func (ie *IndexExpression) End() token.Position  { return after(ie.Rbracket) }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -330,13 +671,74 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression // nil when omitted
+	Stop  Expression // nil when omitted
+	Step  Expression // nil when omitted
+
+	Rbracket token.Position
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return se.Left.Pos() }
+func (se *SliceExpression) End() token.Position  { return after(se.Rbracket) }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.Stop != nil {
+		out.WriteString(se.Stop.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+type MemberExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Pos() token.Position  { return me.Object.Pos() }
+func (me *MemberExpression) End() token.Position  { return me.Property.End() }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	Rbracket token.Position
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return after(al.Rbracket) }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -351,3 +753,62 @@ func (al *ArrayLiteral) String() string {
 
 	return out.String()
 }
+
+// HashPair is a single entry in a HashLiteral. A spread entry (`...other`) is
+// stored with a *SpreadElement as its Key and a nil Value.
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+type HashLiteral struct {
+	Token  token.Token
+	Pairs  []HashPair
+	Rbrace token.Position
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return after(hl.Rbrace) }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, len(hl.Pairs))
+	for i, pair := range hl.Pairs {
+		if pair.Value == nil {
+			pairs[i] = pair.Key.String()
+			continue
+		}
+		pairs[i] = pair.Key.String() + ": " + pair.Value.String()
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+type SpreadElement struct {
+	Token token.Token
+	Value Expression
+}
+
+func (se *SpreadElement) expressionNode()      {}
+func (se *SpreadElement) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadElement) Pos() token.Position  { return se.Token.Pos }
+func (se *SpreadElement) End() token.Position  { return se.Value.End() }
+func (se *SpreadElement) String() string       { return se.TokenLiteral() + se.Value.String() }
+
+// BadExpression is a placeholder for an expression that could not be parsed.
+type BadExpression struct {
+	Token token.Token // the first token of the expression
+	To    token.Position
+}
+
+func (be *BadExpression) expressionNode()      {}
+func (be *BadExpression) TokenLiteral() string { return be.Token.Literal }
+func (be *BadExpression) Pos() token.Position  { return be.Token.Pos }
+func (be *BadExpression) End() token.Position  { return be.To }
+func (be *BadExpression) String() string       { return "<bad expression>" }
@@ -23,3 +23,23 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. Got: %q", s)
 	}
 }
+
+func TestStringLiteralString(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"hello", `"hello"`},
+		{"a\nb\tc", `"a\nb\tc"`},
+		{`say "hi" \o/`, `"say \"hi\" \\o/"`},
+		{"caf\u00e9", `"café"`},
+		{"\x00\x7f", `"\u{0}\u{7f}"`},
+	}
+
+	for _, tt := range tests {
+		lit := &StringLiteral{Value: tt.value}
+		if s := lit.String(); s != tt.expected {
+			t.Errorf("String() wrong for %q. expected=%s, got=%s", tt.value, tt.expected, s)
+		}
+	}
+}
@@ -1,17 +1,23 @@
 package evaluator
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 var builtins = map[string]*Builtin{
 	"len": {
 		Fn: func(args ...Object) Object {
-			if len(args) != 1 {
-				return newError("invalid operation: too many arguments for len (expected %d, found %d)",
-					1, len(args))
+			if err := checkArgumentCount("len", args, 1); err != nil {
+				return err
 			}
 			switch arg := args[0].(type) {
 			case *String:
-				return &Number{Value: float64(len(arg.Value))}
+				return &Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *Array:
-				return &Number{Value: float64(len(arg.Elements))}
+				return &Integer{Value: int64(len(arg.Elements))}
+			case *Hash:
+				return &Integer{Value: int64(len(arg.Pairs))}
 			default:
 				return newError("invalid argument: %s (%s) for len",
 					arg.Inspect(), arg.Type())
@@ -19,3 +25,126 @@ var builtins = map[string]*Builtin{
 		},
 	},
 }
+
+// methods holds the builtin methods available on each object type, called
+// using member syntax (`"abc".upper()`).
+var methods = map[ObjectType]map[string]BuiltinMethod{
+	STRING_OBJ: {
+		"upper": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("upper", args, 0); err != nil {
+				return err
+			}
+			return &String{Value: strings.ToUpper(receiver.(*String).Value)}
+		},
+		"lower": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("lower", args, 0); err != nil {
+				return err
+			}
+			return &String{Value: strings.ToLower(receiver.(*String).Value)}
+		},
+		"trim": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("trim", args, 0); err != nil {
+				return err
+			}
+			return &String{Value: strings.TrimSpace(receiver.(*String).Value)}
+		},
+		"contains": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("contains", args, 1); err != nil {
+				return err
+			}
+			substr, ok := args[0].(*String)
+			if !ok {
+				return newError("invalid argument: %s (%s) for contains",
+					args[0].Inspect(), args[0].Type())
+			}
+			return nativeBoolToBooleanObject(strings.Contains(receiver.(*String).Value, substr.Value))
+		},
+		"split": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("split", args, 1); err != nil {
+				return err
+			}
+			sep, ok := args[0].(*String)
+			if !ok {
+				return newError("invalid argument: %s (%s) for split",
+					args[0].Inspect(), args[0].Type())
+			}
+			parts := strings.Split(receiver.(*String).Value, sep.Value)
+			elements := make([]Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &String{Value: part}
+			}
+			return &Array{Elements: elements}
+		},
+		// bytes returns the UTF-8 encoding of the string, for when its length
+		// or contents are needed in bytes rather than code points.
+		"bytes": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("bytes", args, 0); err != nil {
+				return err
+			}
+			s := receiver.(*String).Value
+			elements := make([]Object, len(s))
+			for i := 0; i < len(s); i++ {
+				elements[i] = &Integer{Value: int64(s[i])}
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	ARRAY_OBJ: {
+		"push": func(receiver Object, args ...Object) Object {
+			array := receiver.(*Array)
+			array.Elements = append(array.Elements, args...)
+			return array
+		},
+		"pop": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("pop", args, 0); err != nil {
+				return err
+			}
+			array := receiver.(*Array)
+			if len(array.Elements) == 0 {
+				return NIL
+			}
+			last := array.Elements[len(array.Elements)-1]
+			array.Elements = array.Elements[:len(array.Elements)-1]
+			return last
+		},
+		"contains": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("contains", args, 1); err != nil {
+				return err
+			}
+			for _, el := range receiver.(*Array).Elements {
+				if evalInfixExpression("==", el, args[0]) == TRUE {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+		"join": func(receiver Object, args ...Object) Object {
+			if err := checkArgumentCount("join", args, 1); err != nil {
+				return err
+			}
+			sep, ok := args[0].(*String)
+			if !ok {
+				return newError("invalid argument: %s (%s) for join",
+					args[0].Inspect(), args[0].Type())
+			}
+			elements := receiver.(*Array).Elements
+			parts := make([]string, len(elements))
+			for i, el := range elements {
+				parts[i] = toString(el)
+			}
+			return &String{Value: strings.Join(parts, sep.Value)}
+		},
+	},
+}
+
+func checkArgumentCount(name string, args []Object, expected int) *Error {
+	switch {
+	case len(args) > expected:
+		return newError("invalid operation: too many arguments for %s (expected %d, found %d)",
+			name, expected, len(args))
+	case len(args) < expected:
+		return newError("invalid operation: not enough arguments for %s (expected %d, found %d)",
+			name, expected, len(args))
+	}
+	return nil
+}
@@ -1,10 +1,19 @@
 package evaluator
 
+// Environment is a scope holding the values of the names declared in it. A
+// scope nested in another can see the names declared in its outer scopes, and
+// can shadow them by declaring the same names itself.
 type Environment struct {
-	store map[string]Object
+	store map[string]binding
 	outer *Environment
 }
 
+// binding is the value of a declared name, and whether it is a constant.
+type binding struct {
+	value    Object
+	constant bool
+}
+
 func NewScopedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
@@ -12,19 +21,64 @@ func NewScopedEnvironment(outer *Environment) *Environment {
 }
 
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
+	s := make(map[string]binding)
 	return &Environment{store: s}
 }
 
-func (e *Environment) Get(name string) (obj Object, ok bool) {
-	obj, ok = e.store[name]
-	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+// Resolve returns the scope name is declared in: this scope, or the nearest
+// outer scope that declares it. It reports false if no scope declares name.
+func (e *Environment) Resolve(name string) (*Environment, bool) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the value of name from the scope it resolves to.
+func (e *Environment) Get(name string) (Object, bool) {
+	env, ok := e.Resolve(name)
+	if !ok {
+		return nil, false
 	}
-	return
+	return env.store[name].value, true
+}
+
+// IsConstant reports whether name resolves to a constant.
+func (e *Environment) IsConstant(name string) bool {
+	env, ok := e.Resolve(name)
+	return ok && env.store[name].constant
 }
 
-func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
-	return val
+// Declare binds name to val in this scope, shadowing any outer declaration of
+// it. It reports false, leaving the scope unchanged, if name is already
+// declared in this scope.
+func (e *Environment) Declare(name string, val Object) bool {
+	return e.declare(name, binding{value: val})
+}
+
+// DeclareConst is like Declare, but the binding can't be assigned to later.
+func (e *Environment) DeclareConst(name string, val Object) bool {
+	return e.declare(name, binding{value: val, constant: true})
+}
+
+func (e *Environment) declare(name string, b binding) bool {
+	if _, ok := e.store[name]; ok {
+		return false
+	}
+	e.store[name] = b
+	return true
+}
+
+// Assign updates name in the scope it resolves to, so that assigning to a
+// variable captured by a closure updates the variable itself. It reports
+// false if name isn't declared or is a constant.
+func (e *Environment) Assign(name string, val Object) bool {
+	env, ok := e.Resolve(name)
+	if !ok || env.store[name].constant {
+		return false
+	}
+	env.store[name] = binding{value: val}
+	return true
 }
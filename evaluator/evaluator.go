@@ -2,17 +2,32 @@ package evaluator
 
 import (
 	"dara/ast"
+	"dara/token"
 	"fmt"
 	"math"
+	"math/big"
+	"strings"
 )
 
 var (
-	NIL   = &Nil{}
-	TRUE  = &Boolean{Value: true}
-	FALSE = &Boolean{Value: false}
+	NIL      = &Nil{}
+	TRUE     = &Boolean{Value: true}
+	FALSE    = &Boolean{Value: false}
+	BREAK    = &Break{}
+	CONTINUE = &Continue{}
 )
 
+// Eval evaluates node in env. Errors are tagged with the position of the
+// innermost node they were raised from.
 func Eval(node ast.Node, env *Environment) Object {
+	obj := eval(node, env)
+	if err, ok := obj.(*Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return obj
+}
+
+func eval(node ast.Node, env *Environment) Object {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -21,13 +36,31 @@ func Eval(node ast.Node, env *Environment) Object {
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 
+	case *ast.IncDecStatement:
+		return evalIncDecStatement(node, env)
+
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 
 	case *ast.IfStatement:
 		return evalIfStatement(node, env)
 
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &ReturnValue{Value: NIL}
+		}
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
@@ -38,12 +71,18 @@ func Eval(node ast.Node, env *Environment) Object {
 	case *ast.NumberLiteral:
 		return &Number{Value: node.Value}
 
+	case *ast.IntegerLiteral:
+		return newInteger(node.Value)
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
 	case *ast.StringLiteral:
 		return &String{Value: node.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -51,6 +90,9 @@ func Eval(node ast.Node, env *Environment) Object {
 		}
 		return &Array{Elements: elements}
 
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -62,6 +104,16 @@ func Eval(node ast.Node, env *Environment) Object {
 		}
 		return evalIndexExpression(left, index)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
+	case *ast.MemberExpression:
+		object := Eval(node.Object, env)
+		if isError(object) {
+			return object
+		}
+		return evalMemberExpression(object, node.Property.Value)
+
 	case *ast.Nil:
 		return &Nil{}
 
@@ -97,6 +149,13 @@ func Eval(node ast.Node, env *Environment) Object {
 		body := node.Body
 		return &Function{Parameters: params, Env: env, Body: body}
 
+	case *ast.FunctionStatement:
+		// The function was bound by hoistFunctions when its block was entered.
+		return NIL
+
+	case *ast.ConstStatement:
+		return evalConstStatement(node, env)
+
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
 		if isError(function) {
@@ -106,7 +165,20 @@ func Eval(node ast.Node, env *Environment) Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		// An arity error is reported at the call, without a frame for the
+		// function as it never started.
+		if fn, ok := function.(*Function); ok {
+			if err := checkArity(fn, args); err != nil {
+				return err
+			}
+		}
+		result := applyFunction(function, args)
+		if err, ok := result.(*Error); ok {
+			if fn, ok := function.(*Function); ok {
+				err.Stack = append(err.Stack, Frame{Function: fn.Name, Pos: node.Pos()})
+			}
+		}
+		return result
 	}
 
 	return nil
@@ -124,6 +196,10 @@ func newError(format string, a ...interface{}) *Error {
 }
 
 func evalProgram(program *ast.Program, env *Environment) Object {
+	if err := hoistFunctions(program.Statements, env); err != nil {
+		return err
+	}
+
 	var result Object
 
 	for _, statement := range program.Statements {
@@ -134,20 +210,36 @@ func evalProgram(program *ast.Program, env *Environment) Object {
 			return r.Value
 		case *Error:
 			return result
+		case *Break, *Continue:
+			return newError("invalid operation: %s outside of a loop", r.Inspect())
 		}
 	}
 
 	return result
 }
 
+// evalBlockStatement evaluates block in a scope of its own, so names declared
+// in it are only visible inside it, and may shadow names declared outside it.
 func evalBlockStatement(block *ast.BlockStatement, env *Environment) Object {
+	return evalStatements(block, NewScopedEnvironment(env))
+}
+
+// evalStatements evaluates the statements of block directly in env. Function
+// bodies are evaluated this way, so that they share a scope with the
+// function's parameters.
+func evalStatements(block *ast.BlockStatement, env *Environment) Object {
+	if err := hoistFunctions(block.Statements, env); err != nil {
+		return err
+	}
+
 	var result Object
 
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
 		if result != nil {
-			if rt := result.Type(); rt == RETURN_VALUE_OBJ || rt == ERROR_OBJ {
+			switch result.Type() {
+			case RETURN_VALUE_OBJ, ERROR_OBJ, BREAK_OBJ, CONTINUE_OBJ:
 				return result
 			}
 		}
@@ -156,6 +248,30 @@ func evalBlockStatement(block *ast.BlockStatement, env *Environment) Object {
 	return result
 }
 
+// hoistFunctions binds the functions declared in a block before any of its
+// statements run, so that they can be called from anywhere in the block,
+// including by each other.
+func hoistFunctions(statements []ast.Statement, env *Environment) *Error {
+	for _, statement := range statements {
+		fs, ok := statement.(*ast.FunctionStatement)
+		if !ok {
+			continue
+		}
+		fn := &Function{
+			Name:       fs.Name.Value,
+			Parameters: fs.Function.Parameters,
+			Body:       fs.Function.Body,
+			Env:        env,
+		}
+		if !env.Declare(fs.Name.Value, fn) {
+			err := newError("invalid operation: can not redeclare %s", fs.Name.Value)
+			err.Pos = fs.Name.Pos()
+			return err
+		}
+	}
+	return nil
+}
+
 func evalIfStatement(is *ast.IfStatement, env *Environment) Object {
 	condition := Eval(is.Condition, env)
 	if isError(condition) {
@@ -175,6 +291,107 @@ func evalIfStatement(is *ast.IfStatement, env *Environment) Object {
 	return NIL
 }
 
+func evalForStatement(fs *ast.ForStatement, env *Environment) Object {
+	loopEnv := NewScopedEnvironment(env)
+
+	if fs.Init != nil {
+		if init := Eval(fs.Init, loopEnv); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+
+			if condition.Type() != BOOLEAN_OBJ {
+				return newError("type mismatch: non-boolean condition %s (%s) in for statement",
+					condition.Inspect(), condition.Type())
+			}
+			if condition == FALSE {
+				return NIL
+			}
+		}
+
+		if result, done := evalLoopBody(fs.Body, loopEnv); done {
+			return result
+		}
+
+		if fs.Post != nil {
+			if post := Eval(fs.Post, loopEnv); isError(post) {
+				return post
+			}
+		}
+	}
+}
+
+func evalForInStatement(fs *ast.ForInStatement, env *Environment) Object {
+	iterable := Eval(fs.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	// Each iteration gets its own scope holding the loop variables, which the
+	// body's scope is nested in. Ranging over an array or object visits the
+	// elements it had when the loop started.
+	iteration := func(key, value Object) (Object, bool) {
+		scope := NewScopedEnvironment(env)
+		if fs.Key != nil {
+			scope.Declare(fs.Key.Value, key)
+		}
+		scope.Declare(fs.Value.Value, value)
+		return evalLoopBody(fs.Body, scope)
+	}
+
+	switch iterable := iterable.(type) {
+	case *Array:
+		for i, el := range iterable.Elements {
+			if result, done := iteration(&Integer{Value: int64(i)}, el); done {
+				return result
+			}
+		}
+	case *String:
+		// Strings are ranged over by code point, counting from 0.
+		for i, char := range []rune(iterable.Value) {
+			if result, done := iteration(&Integer{Value: int64(i)}, &String{Value: string(char)}); done {
+				return result
+			}
+		}
+	case *Hash:
+		// A single loop variable over an object binds its keys.
+		for _, hashKey := range iterable.Keys {
+			pair := iterable.Pairs[hashKey]
+			key, value := pair.Key, pair.Value
+			if fs.Key == nil {
+				value = key
+			}
+			if result, done := iteration(key, value); done {
+				return result
+			}
+		}
+	default:
+		return newError("invalid operation: can not range over %s (%s)",
+			iterable.Inspect(), iterable.Type())
+	}
+
+	return NIL
+}
+
+// evalLoopBody evaluates a single iteration of a loop body, reporting whether
+// the loop should stop along with the object it should evaluate to.
+func evalLoopBody(body *ast.BlockStatement, env *Environment) (Object, bool) {
+	switch result := Eval(body, env).(type) {
+	case *ReturnValue, *Error:
+		return result, true
+	case *Break:
+		return NIL, true
+	}
+	return nil, false
+}
+
 func nativeBoolToBooleanObject(input bool) *Boolean {
 	if input {
 		return TRUE
@@ -196,8 +413,11 @@ func evalPrefixExpression(operator string, right Object) Object {
 
 func evalInfixExpression(operator string, left, right Object) Object {
 	switch {
-	case left.Type() == NUMBER_OBJ && right.Type() == NUMBER_OBJ:
-		return evalArithmeticInfixExpression(operator, left, right)
+	case left.Type() == INTEGER_OBJ && right.Type() == INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case isNumeric(left) && isNumeric(right):
+		// Mixing an integer with a float gives a float.
+		return evalArithmeticInfixExpression(operator, toNumber(left), toNumber(right))
 	case left.Type() == STRING_OBJ && right.Type() == STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -213,29 +433,203 @@ func evalInfixExpression(operator string, left, right Object) Object {
 }
 
 func evalDeclareExpression(node *ast.DeclareExpression, env *Environment) Object {
-	if _, ok := env.Get(node.Name.Value); ok {
+	// The value is evaluated before the name is declared, so that it can
+	// refer to a name from an outer scope that the declaration shadows.
+	if scope, ok := env.Resolve(node.Name.Value); ok && scope == env {
 		return newError("invalid operation: can not redeclare %s", node.Name.Value)
 	}
 	val := Eval(node.Value, env)
 	if isError(val) {
 		return val
 	}
-	env.Set(node.Name.Value, val)
+	if fn, ok := val.(*Function); ok && fn.Name == "" {
+		fn.Name = node.Name.Value
+	}
+	env.Declare(node.Name.Value, val)
 	return val
 }
 
+func evalConstStatement(node *ast.ConstStatement, env *Environment) Object {
+	if scope, ok := env.Resolve(node.Name.Value); ok && scope == env {
+		return newError("invalid operation: can not redeclare %s", node.Name.Value)
+	}
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	if fn, ok := val.(*Function); ok && fn.Name == "" {
+		fn.Name = node.Name.Value
+	}
+	env.DeclareConst(node.Name.Value, val)
+	return NIL
+}
+
 func evalAssignExpression(node *ast.AssignExpression, env *Environment) Object {
-	if _, ok := env.Get(node.Name.Value); !ok {
-		return newError("undeclared name: %s", node.Name.Value)
+	ref, err := evalReference(node.Target, env)
+	if err != nil {
+		return err
+	}
+
+	var current Object
+	if node.Operator != "" {
+		current = ref.get()
 	}
+
 	val := Eval(node.Value, env)
 	if isError(val) {
 		return val
 	}
-	env.Set(node.Name.Value, val)
+	if node.Operator != "" {
+		val = evalInfixExpression(node.Operator, current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	if err := ref.set(val); err != nil {
+		return err
+	}
+	return val
+}
+
+func evalIncDecStatement(node *ast.IncDecStatement, env *Environment) Object {
+	ref, err := evalReference(node.Target, env)
+	if err != nil {
+		return err
+	}
+
+	current := ref.get()
+	if !isNumeric(current) {
+		return newError("invalid operation: operator %s is not defined for %s (%s)",
+			node.TokenLiteral(), current.Inspect(), current.Type())
+	}
+
+	operator := "+"
+	if node.Token.Type == token.DEC {
+		operator = "-"
+	}
+	val := evalInfixExpression(operator, current, &Integer{Value: 1})
+	if err := ref.set(val); err != nil {
+		return err
+	}
 	return val
 }
 
+// reference is somewhere a value can be assigned: a variable, an element of
+// an array, or a key of an object.
+type reference struct {
+	env  *Environment // the scope the variable is declared in
+	name string
+
+	array *Array
+	index int
+
+	hash *Hash
+	key  Hashable
+}
+
+// evalReference evaluates the parts of an assignment target, so that it can
+// be read and then written without evaluating them twice. Writing to an array
+// element requires the index to be in range, while writing to an object key
+// adds it if it is missing.
+func evalReference(target ast.Expression, env *Environment) (*reference, *Error) {
+	var container, index Object
+
+	switch target := target.(type) {
+	case *ast.Identifier:
+		scope, ok := env.Resolve(target.Value)
+		if !ok {
+			return nil, newError("undeclared name: %s", target.Value)
+		}
+		if scope.IsConstant(target.Value) {
+			return nil, newError("invalid operation: can not assign to constant %s", target.Value)
+		}
+		return &reference{env: scope, name: target.Value}, nil
+	case *ast.IndexExpression:
+		container = Eval(target.Left, env)
+		if isError(container) {
+			return nil, container.(*Error)
+		}
+		index = Eval(target.Index, env)
+		if isError(index) {
+			return nil, index.(*Error)
+		}
+	case *ast.MemberExpression:
+		container = Eval(target.Object, env)
+		if isError(container) {
+			return nil, container.(*Error)
+		}
+		if container.Type() != HASH_OBJ {
+			return nil, newError("invalid operation: can not assign to member %s of %s (%s)",
+				target.Property.Value, container.Inspect(), container.Type())
+		}
+		index = &String{Value: target.Property.Value}
+	default:
+		return nil, newError("invalid operation: can not assign to %s", target)
+	}
+
+	switch container := container.(type) {
+	case *Array:
+		if !isNumeric(index) {
+			return nil, newError("type mismatch: non-number %s (%s) can not index an array",
+				index.Inspect(), index.Type())
+		}
+		i, err := toIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		resolved, ok := resolveIndex(i, len(container.Elements))
+		if !ok {
+			return nil, indexOutOfRange(i, len(container.Elements))
+		}
+		return &reference{array: container, index: resolved}, nil
+	case *Hash:
+		key, ok := index.(Hashable)
+		if !ok {
+			return nil, newError("invalid argument: %s (%s) can not be used as an object key",
+				index.Inspect(), index.Type())
+		}
+		return &reference{hash: container, key: key}, nil
+	default:
+		return nil, newError("invalid operation: can not assign to an index of %s (%s)",
+			container.Inspect(), container.Type())
+	}
+}
+
+func indexOutOfRange(i, length int) *Error {
+	return newError("invalid argument: index %d out of range for array of length %d", i, length)
+}
+
+func (r *reference) get() Object {
+	switch {
+	case r.array != nil:
+		return r.array.Elements[r.index]
+	case r.hash != nil:
+		if value, ok := r.hash.Get(r.key); ok {
+			return value
+		}
+		return NIL
+	}
+	value, _ := r.env.Get(r.name)
+	return value
+}
+
+func (r *reference) set(value Object) *Error {
+	switch {
+	case r.array != nil:
+		// The array may have shrunk while the value was evaluated.
+		if r.index >= len(r.array.Elements) {
+			return indexOutOfRange(r.index, len(r.array.Elements))
+		}
+		r.array.Elements[r.index] = value
+	case r.hash != nil:
+		r.hash.Set(r.key, value)
+	default:
+		r.env.Assign(r.name, value)
+	}
+	return nil
+}
+
 func evalIdentifier(node *ast.Identifier, env *Environment) Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -250,51 +644,364 @@ func evalExpressions(exps []ast.Expression, env *Environment) []Object {
 	var result []Object
 
 	for _, e := range exps {
+		spread, isSpread := e.(*ast.SpreadElement)
+		if isSpread {
+			e = spread.Value
+		}
+
 		evaluated := Eval(e, env)
 		if isError(evaluated) {
 			return []Object{evaluated}
 		}
-		result = append(result, evaluated)
+
+		if !isSpread {
+			result = append(result, evaluated)
+			continue
+		}
+
+		array, ok := evaluated.(*Array)
+		if !ok {
+			err := newError("invalid operation: can not spread %s (%s) into a list",
+				evaluated.Inspect(), evaluated.Type())
+			return []Object{err}
+		}
+		result = append(result, array.Elements...)
 	}
 
 	return result
 }
 
+func evalInterpolatedString(node *ast.InterpolatedString, env *Environment) Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		evaluated := Eval(part, env)
+		if isError(evaluated) {
+			return evaluated
+		}
+		out.WriteString(toString(evaluated))
+	}
+
+	return &String{Value: out.String()}
+}
+
+// toString converts obj to a string the way it is shown when interpolated or
+// joined: strings as they are, and anything else as it would be inspected.
+func toString(obj Object) string {
+	if str, ok := obj.(*String); ok {
+		return str.Value
+	}
+	return obj.Inspect()
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *Environment) Object {
+	hash := NewHash()
+
+	for _, pair := range node.Pairs {
+		if spread, ok := pair.Key.(*ast.SpreadElement); ok {
+			value := Eval(spread.Value, env)
+			if isError(value) {
+				return value
+			}
+
+			other, ok := value.(*Hash)
+			if !ok {
+				return newError("invalid operation: can not spread %s (%s) into an object",
+					value.Inspect(), value.Type())
+			}
+
+			for _, hashKey := range other.Keys {
+				otherPair := other.Pairs[hashKey]
+				hash.Set(otherPair.Key.(Hashable), otherPair.Value)
+			}
+			continue
+		}
+
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(Hashable)
+		if !ok {
+			return newError("invalid argument: %s (%s) can not be used as an object key",
+				key.Inspect(), key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
 func evalIndexExpression(left, index Object) Object {
 	switch {
-	case left.Type() == ARRAY_OBJ && index.Type() == NUMBER_OBJ:
+	case left.Type() == ARRAY_OBJ && isNumeric(index):
 		return evalArrayIndexExpression(left, index)
-	default:
+	case left.Type() == ARRAY_OBJ:
 		return newError("type mismatch: non-number %s (%s) can not index an array",
 			index.Inspect(), index.Type())
+	case left.Type() == STRING_OBJ && isNumeric(index):
+		return evalStringIndexExpression(left, index)
+	case left.Type() == STRING_OBJ:
+		return newError("type mismatch: non-number %s (%s) can not index a string",
+			index.Inspect(), index.Type())
+	case left.Type() == HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("invalid operation: can not index %s (%s)",
+			left.Inspect(), left.Type())
+	}
+}
+
+func evalHashIndexExpression(hash, index Object) Object {
+	key, ok := index.(Hashable)
+	if !ok {
+		return newError("invalid argument: %s (%s) can not be used as an object key",
+			index.Inspect(), index.Type())
 	}
+
+	if value, ok := hash.(*Hash).Get(key); ok {
+		return value
+	}
+
+	return NIL
 }
 
 func evalArrayIndexExpression(array, index Object) Object {
+	a := array.(*Array)
+
+	i, err := toIndex(index)
+	if err != nil {
+		return err
+	}
+
+	i, ok := resolveIndex(i, len(a.Elements))
+	if !ok {
+		return NIL
+	}
+
+	return a.Elements[i]
+}
+
+func evalStringIndexExpression(str, index Object) Object {
+	s := []rune(str.(*String).Value)
+
+	i, err := toIndex(index)
+	if err != nil {
+		return err
+	}
+
+	i, ok := resolveIndex(i, len(s))
+	if !ok {
+		return NIL
+	}
+
+	return &String{Value: string(s[i])}
+}
+
+// toIndex converts a numeric index to an int. A float is accepted if it is a
+// whole number. An index too large for an int is clamped, as it is out of
+// range either way.
+func toIndex(index Object) (int, *Error) {
+	var i *big.Int
+
+	switch index := index.(type) {
+	case *Integer:
+		if index.Big == nil {
+			return int(index.Value), nil
+		}
+		i = index.Big
+	case *Number:
+		if index.Value != math.Trunc(index.Value) || math.IsInf(index.Value, 0) {
+			return 0, newError("invalid argument: index %s (%s) must be an integer",
+				index.Inspect(), index.Type())
+		}
+		i, _ = big.NewFloat(index.Value).Int(nil)
+	}
+
+	switch {
+	case i.IsInt64():
+		return int(i.Int64()), nil
+	case i.Sign() < 0:
+		return math.MinInt64, nil
+	default:
+		return math.MaxInt64, nil
+	}
+}
+
+// resolveIndex turns a possibly negative index into an offset from the start
+// of a sequence, where -1 is the last element. It reports false if the index
+// is out of range.
+func resolveIndex(i, length int) (int, bool) {
+	if i < 0 {
+		i += length
+	}
+	return i, i >= 0 && i < length
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *Environment) Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	var bounds [3]Object
+	for i, exp := range []ast.Expression{node.Start, node.Stop, node.Step} {
+		if exp == nil {
+			continue
+		}
+		bound := Eval(exp, env)
+		if isError(bound) {
+			return bound
+		}
+		bounds[i] = bound
+	}
+
+	switch left := left.(type) {
+	case *Array:
+		indices, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]Object, len(indices))
+		for i, index := range indices {
+			elements[i] = left.Elements[index]
+		}
+		return &Array{Elements: elements}
+	case *String:
+		chars := []rune(left.Value)
+		indices, err := sliceIndices(len(chars), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		var out strings.Builder
+		for _, index := range indices {
+			out.WriteRune(chars[index])
+		}
+		return &String{Value: out.String()}
+	default:
+		return newError("invalid operation: can not slice %s (%s)",
+			left.Inspect(), left.Type())
+	}
+}
+
+// sliceIndices returns the indices selected by slicing a sequence of the given
+// length, following the same rules as Python. Any bound may be nil to use its
+// default. Negative bounds count from the end of the sequence, bounds that are
+// out of range are clamped to it, and a negative step walks backwards from the
+// end.
+func sliceIndices(length int, start, end, step Object) ([]int, *Error) {
 	var (
-		a   = array.(*Array)
-		i   = int(index.(*Number).Value)
-		max = len(a.Elements) - 1
+		from = 0
+		to   = length
+		by   = 1
 	)
 
-	// TODO: error if decimals on index?
-	// https://stackoverflow.com/a/16534885
+	bound := func(obj Object) (int, *Error) {
+		if !isNumeric(obj) {
+			return 0, newError("type mismatch: non-number %s (%s) can not be used as a slice bound",
+				obj.Inspect(), obj.Type())
+		}
+		return toIndex(obj)
+	}
+
+	if step != nil {
+		var err *Error
+		if by, err = bound(step); err != nil {
+			return nil, err
+		}
+		if by == 0 {
+			return nil, newError("invalid argument: slice step can not be zero")
+		}
+		// Any step longer than the sequence takes at most one element, so
+		// limit it to keep i += by below from overflowing.
+		if by > length {
+			by = length + 1
+		} else if by < -length {
+			by = -length - 1
+		}
+	}
+
+	// clamp resolves a bound the same way for both ends of the slice. Walking
+	// backwards, -1 (before the first element) is a valid place to stop.
+	clamp := func(i int) int {
+		if i < 0 {
+			i += length
+			if i < 0 {
+				if by < 0 {
+					return -1
+				}
+				return 0
+			}
+		} else if i >= length {
+			if by < 0 {
+				return length - 1
+			}
+			return length
+		}
+		return i
+	}
+
+	if by < 0 {
+		from, to = length-1, -1
+	}
+
+	if start != nil {
+		i, err := bound(start)
+		if err != nil {
+			return nil, err
+		}
+		from = clamp(i)
+	}
+
+	if end != nil {
+		i, err := bound(end)
+		if err != nil {
+			return nil, err
+		}
+		to = clamp(i)
+	}
 
-	if i < 0 || i > max {
+	var indices []int
+	for i := from; (by > 0 && i < to) || (by < 0 && i > to); i += by {
+		indices = append(indices, i)
+	}
+
+	return indices, nil
+}
+
+func evalMemberExpression(object Object, name string) Object {
+	if hash, ok := object.(*Hash); ok {
+		if value, ok := hash.Get(&String{Value: name}); ok {
+			return value
+		}
 		return NIL
 	}
 
-	return a.Elements[i]
+	if method, ok := methods[object.Type()][name]; ok {
+		return &Builtin{Fn: func(args ...Object) Object {
+			return method(object, args...)
+		}}
+	}
+
+	return newError("invalid operation: %s (%s) has no member %s",
+		object.Inspect(), object.Type(), name)
 }
 
 func applyFunction(fn Object, args []Object) Object {
 	switch function := fn.(type) {
 	case *Function:
-		var (
-			extendedEnv = extendedFunctionEnv(function, args)
-			evaluated   = Eval(function.Body, extendedEnv)
-		)
-		return unwrapReturnValue(evaluated)
+		extendedEnv, err := extendedFunctionEnv(function, args)
+		if err != nil {
+			return err
+		}
+		return unwrapReturnValue(evalStatements(function.Body, extendedEnv))
 	case *Builtin:
 		return function.Fn(args...)
 	default:
@@ -302,19 +1009,83 @@ func applyFunction(fn Object, args []Object) Object {
 	}
 }
 
-func extendedFunctionEnv(fn *Function, args []Object) *Environment {
+// checkArity reports an error unless args has an argument for every
+// parameter of fn without a default value, and no more than it has
+// parameters unless it has a rest parameter.
+func checkArity(fn *Function, args []Object) *Error {
+	var (
+		name     = fn.Name
+		required = 0
+		max      = len(fn.Parameters)
+		variadic = false
+	)
+	if name == "" {
+		name = "fn"
+	}
+	for _, param := range fn.Parameters {
+		switch {
+		case param.Rest:
+			variadic = true
+		case param.Default == nil:
+			required++
+		}
+	}
+
+	switch {
+	case len(args) < required && required == max:
+		return newError("invalid operation: not enough arguments for %s (expected %d, found %d)",
+			name, required, len(args))
+	case len(args) < required:
+		return newError("invalid operation: not enough arguments for %s (expected at least %d, found %d)",
+			name, required, len(args))
+	case variadic:
+		return nil
+	case len(args) > max && required == max:
+		return newError("invalid operation: too many arguments for %s (expected %d, found %d)",
+			name, max, len(args))
+	case len(args) > max:
+		return newError("invalid operation: too many arguments for %s (expected at most %d, found %d)",
+			name, max, len(args))
+	}
+	return nil
+}
+
+// extendedFunctionEnv binds the arguments of a call to fn's parameters. A
+// parameter without an argument takes its default value, evaluated at the
+// time of the call, so defaults can refer to earlier parameters. A rest
+// parameter gets an array of the remaining arguments.
+func extendedFunctionEnv(fn *Function, args []Object) (*Environment, Object) {
 	env := NewScopedEnvironment(fn.Env)
 
 	for i, param := range fn.Parameters {
-		env.Set(param.Value, args[i])
+		if param.Rest {
+			rest := []Object{}
+			if i < len(args) {
+				rest = append(rest, args[i:]...)
+			}
+			env.Declare(param.Name.Value, &Array{Elements: rest})
+			continue
+		}
+		if i < len(args) {
+			env.Declare(param.Name.Value, args[i])
+			continue
+		}
+		value := Eval(param.Default, env)
+		if isError(value) {
+			return nil, value
+		}
+		env.Declare(param.Name.Value, value)
 	}
 
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj Object) Object {
-	if returnValue, ok := obj.(*ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *ReturnValue:
+		return obj.Value
+	case *Break, *Continue:
+		return newError("invalid operation: %s outside of a loop", obj.Inspect())
 	}
 	return obj
 }
@@ -332,13 +1103,107 @@ func evalBangOperatorExpression(right Object) Object {
 }
 
 func evalMinusPrefixOperatorExpression(right Object) Object {
-	if right.Type() != NUMBER_OBJ {
+	switch right := right.(type) {
+	case *Integer:
+		if right.Big == nil && right.Value != math.MinInt64 {
+			return &Integer{Value: -right.Value}
+		}
+		return newInteger(new(big.Int).Neg(right.BigInt()))
+	case *Number:
+		return &Number{Value: -right.Value}
+	default:
 		return newError("invalid operation: operator %s is not defined for %s (%s)",
 			"-", right.Inspect(), right.Type())
 	}
+}
+
+func isNumeric(obj Object) bool {
+	return obj.Type() == INTEGER_OBJ || obj.Type() == NUMBER_OBJ
+}
+
+// toNumber converts a numeric object to a float Number.
+func toNumber(obj Object) *Number {
+	if i, ok := obj.(*Integer); ok {
+		return &Number{Value: i.Float()}
+	}
+	return obj.(*Number)
+}
+
+// evalIntegerInfixExpression evaluates an operator on two integers. `+`, `-`,
+// `*` and `%` give an integer, which is computed with int64s where the result
+// fits and big.Ints where it doesn't. `/` always gives a float.
+func evalIntegerInfixExpression(operator string, left, right Object) Object {
+	var (
+		l = left.(*Integer)
+		r = right.(*Integer)
+	)
 
-	value := right.(*Number).Value
-	return &Number{Value: -value}
+	if l.Big == nil && r.Big == nil {
+		a, b := l.Value, r.Value
+		switch operator {
+		case "+":
+			if sum := a + b; (sum > a) == (b > 0) {
+				return &Integer{Value: sum}
+			}
+		case "-":
+			if diff := a - b; (diff < a) == (b > 0) {
+				return &Integer{Value: diff}
+			}
+		case "*":
+			product := a * b
+			if a == 0 || product/a == b && !(a == -1 && b == math.MinInt64) {
+				return &Integer{Value: product}
+			}
+		case "%":
+			if b != 0 {
+				return &Integer{Value: a % b}
+			}
+		case "<":
+			return nativeBoolToBooleanObject(a < b)
+		case ">":
+			return nativeBoolToBooleanObject(a > b)
+		case "<=":
+			return nativeBoolToBooleanObject(a <= b)
+		case ">=":
+			return nativeBoolToBooleanObject(a >= b)
+		case "==":
+			return nativeBoolToBooleanObject(a == b)
+		case "!=":
+			return nativeBoolToBooleanObject(a != b)
+		}
+	}
+
+	a, b := l.BigInt(), r.BigInt()
+	switch operator {
+	case "+":
+		return newInteger(new(big.Int).Add(a, b))
+	case "-":
+		return newInteger(new(big.Int).Sub(a, b))
+	case "*":
+		return newInteger(new(big.Int).Mul(a, b))
+	case "%":
+		if b.Sign() == 0 {
+			return newError("invalid operation: division by zero")
+		}
+		return newInteger(new(big.Int).Rem(a, b))
+	case "/":
+		return evalArithmeticInfixExpression(operator, toNumber(left), toNumber(right))
+	case "<":
+		return nativeBoolToBooleanObject(a.Cmp(b) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(a.Cmp(b) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(a.Cmp(b) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(a.Cmp(b) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(a.Cmp(b) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(a.Cmp(b) != 0)
+	default:
+		return newError("invalid operation: operator %s is not defined for %s (%s)",
+			operator, right.Inspect(), right.Type())
+	}
 }
 
 func evalArithmeticInfixExpression(operator string, left, right Object) Object {
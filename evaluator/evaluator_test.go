@@ -9,7 +9,7 @@ import (
 func TestEvalNumberExpression(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected interface{}
 	}{
 		{"5", 5},
 		{"10.5", 10.5},
@@ -18,8 +18,8 @@ func TestEvalNumberExpression(t *testing.T) {
 		{"6 % 2", 0},
 		{"6 % 4", 2},
 		{"6.2 % 4", 2.2},
-		{"5.5 + 5.5", 11},
-		{"5.5 * 2", 11},
+		{"5.5 + 5.5", 11.0},
+		{"5.5 * 2", 11.0},
 		{"5.5 + 5.4", 10.9},
 		{"5 + 5 + 5 + 5 - 10", 10},
 		{"2 * 2 * 2 * 2 * 2", 32},
@@ -27,19 +27,74 @@ func TestEvalNumberExpression(t *testing.T) {
 		{"5 * 2 + 10", 20},
 		{"5 + 2 * 10", 25},
 		{"20 + 2 * -10", 0},
-		{"50 / 2 * 2 + 10", 60},
+		{"50 / 2 * 2 + 10", 60.0},
 		{"2 * (5 + 10)", 30},
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
-		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50.0},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testNumberObject(t, evaluated, tt.expected)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testNumberObject(t, evaluated, expected)
+		}
 	}
 }
 
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9007199254740993", "9007199254740993"},
+		{"9007199254740992 + 1", "9007199254740993"},
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775808 - 1", "-9223372036854775809"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"(9223372036854775807 + 1) - 1", "9223372036854775807"},
+		{"0xffffffffffffffffff % 1000", "695"},
+		{"-7 % 3", "-1"},
+		{"100000000000000000000 > 99999999999999999999", "true"},
+		{"100000000000000000000 == 100000000000000000000", "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s (%T)",
+				tt.input, tt.expected, evaluated.Inspect(), evaluated)
+		}
+	}
+
+	// Results that fit in an int64 are stored as one again.
+	testIntegerObject(t, testEval("(9223372036854775807 + 1) - 1"), 9223372036854775807)
+}
+
+func TestMixedNumberArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2", 3.5},
+		{"6 / 3", 2},
+		{"1 - 1.0", 0},
+	}
+
+	for _, tt := range tests {
+		testNumberObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testBooleanObject(t, testEval("2 == 2.0"), true)
+	testBooleanObject(t, testEval("2 < 2.5"), true)
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -99,6 +154,25 @@ func TestEvalStringExpression(t *testing.T) {
 	}
 }
 
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`name := "dara"; "hello ${name}!"`, "hello dara!"},
+		{`items := [1, "a"]; "${len(items)} items: ${items}"`, `2 items: [1, "a"]`},
+		{`user := {name: "x"}; "${user.name}${user.name}"`, "xx"},
+		{`"${nil} ${true} ${1.5}"`, "nil true 1.5"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"\${not interpolated}"`, "${not interpolated}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testStringObject(t, evaluated, tt.expected)
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -137,17 +211,92 @@ func TestIfElseExpression(t *testing.T) {
 		evaluated := testEval(tt.input)
 		integer, ok := tt.expected.(int)
 		if ok {
-			testNumberObject(t, evaluated, float64(integer))
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"i := 0; for i < 10 { i = i + 1; }; i", 10},
+		{"sum := 0; for i := 0; i < 5; i = i + 1 { sum = sum + i; }; sum", 10},
+		{"sum := 0; for i := 0; i < 5; i = i + 1 { x := i * 2; sum = sum + x; }; sum", 20},
+		{"i := 0; for { i = i + 1; if i == 3 { break; } }; i", 3},
+		{"sum := 0; for i := 0; i < 5; i = i + 1 { if i % 2 == 0 { continue; } sum = sum + i; }; sum", 4},
+		{"for i := 0; i < 5; i = i + 1 { }; for i := 0; i < 5; i = i + 1 { }", nil},
+		{"for false { }", nil},
+		{"i := 0; for i < 100000 { i = i + 1; }; i", 100000},
+		{`
+		n := 0;
+		for i := 0; i < 3; i = i + 1 {
+			for j := 0; j < 3; j = j + 1 {
+				if j == 2 { break; }
+				n = n + 1;
+			}
+		}
+		n`, 6},
+		{`
+		find := fn(arr, x) {
+			for i, v in arr {
+				if v == x { return i; }
+			}
+			return -1;
+		};
+		find([4, 5, 6], 6)`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
 		} else {
 			testNilObject(t, evaluated)
 		}
 	}
 }
 
+func TestForInStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`sum := 0; for v in [1, 2, 3] { sum = sum + v; }; sum`, 6},
+		{`sum := 0; for i, v in [1, 2, 3] { sum = sum + i * v; }; sum`, 8},
+		{`s := ""; for c in "abc" { s = c + s; }; s`, "cba"},
+		{`s := ""; for i, c in "abc" { if i > 0 { s = s + c; } }; s`, "bc"},
+		{`s := ""; for c in "héllo" { s = c + s; }; s`, "olléh"},
+		{`n := 0; for i, c in "日本語" { n = i; }; n`, 2},
+		{`s := ""; for k in {a: 1, b: 2} { s = s + k; }; s`, "ab"},
+		{`sum := 0; for k, v in {a: 1, b: 2} { sum = sum + v; }; sum`, 3},
+		{`arr := [1, 2]; for v in arr { arr.push(v); }; len(arr)`, 4},
+		{`n := 0; for v in [1, 2, 3, 4] { if v == 3 { break; } n = n + 1; }; n`, 2},
+		{`v := 10; for v in [1, 2] { }; v`, 10},
+		{`for v in [] { }`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
 func TestDefineExpression(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected int64
 	}{
 		{"a := 5; a;", 5},
 		{"a := 5 * 5; a;", 25},
@@ -157,14 +306,140 @@ func TestDefineExpression(t *testing.T) {
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testNumberObject(t, evaluated, tt.expected)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"const a = 5; a", 5},
+		{"const a = 5; b := a * 2; b", 10},
+		{"const a = 5; if true { a := 1; a += 1; a }", 2},
+		{"const a = 5; if true { const a = 1 }; a", 5},
+		{"const xs = [1, 2]; xs[0] = 3; xs[0]", 3},
+		{"const f = fn(n) { n + 1 }; f(1)", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBlockScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if true { x := 1 }; if true { x := 2; x }", 2},
+		{"x := 1; if true { x := 2 }; x", 1},
+		{"x := 1; if true { x := 2; x += 1; x }", 3},
+		{"x := 1; if true { x = 2 }; x", 2},
+		{"x := 1; if false { } else { x := 5; x }", 5},
+		{"x := 1; if false { } else if true { x := 5 }; x", 1},
+		{"n := 0; for i := 0; i < 3; i++ { i := 10; n += i }; n", 30},
+		{"n := 0; for v in [1, 2] { v := v * 10; n += v }; n", 30},
+		{"n := 0; for i := 0; i < 2; i++ { x := i; n += x }; n", 1},
+		{"x := 1; f := fn() { x := 2; x }; [f(), x][0] + [f(), x][1]", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, int64(tt.expected.(int)))
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	inner := NewScopedEnvironment(outer)
+
+	if !outer.Declare("x", &Integer{Value: 1}) {
+		t.Fatalf("outer.Declare(x) failed")
+	}
+	if outer.Declare("x", &Integer{Value: 2}) {
+		t.Errorf("outer.Declare(x) succeeded twice")
+	}
+	if scope, ok := inner.Resolve("x"); !ok || scope != outer {
+		t.Errorf("inner.Resolve(x) wrong. got=%p, %t, want=%p", scope, ok, outer)
+	}
+
+	// Assigning through the inner scope updates the outer declaration.
+	if !inner.Assign("x", &Integer{Value: 3}) {
+		t.Fatalf("inner.Assign(x) failed")
+	}
+	x, _ := outer.Get("x")
+	testIntegerObject(t, x, 3)
+
+	// Declaring in the inner scope shadows the outer one.
+	if !inner.Declare("x", &Integer{Value: 4}) {
+		t.Fatalf("inner.Declare(x) failed")
+	}
+	x, _ = inner.Get("x")
+	testIntegerObject(t, x, 4)
+	x, _ = outer.Get("x")
+	testIntegerObject(t, x, 3)
+
+	if inner.Assign("y", NIL) {
+		t.Errorf("inner.Assign(y) succeeded for an undeclared name")
+	}
+	if _, ok := inner.Resolve("y"); ok {
+		t.Errorf("inner.Resolve(y) found an undeclared name")
+	}
+
+	if !outer.DeclareConst("c", &Integer{Value: 5}) {
+		t.Fatalf("outer.DeclareConst(c) failed")
+	}
+	if !inner.IsConstant("c") || inner.IsConstant("x") {
+		t.Errorf("inner.IsConstant wrong. got c=%t, x=%t", inner.IsConstant("c"), inner.IsConstant("x"))
+	}
+	if inner.Assign("c", NIL) {
+		t.Errorf("inner.Assign(c) succeeded for a constant")
+	}
+	if val, _ := inner.Get("c"); val.(*Integer).Value != 5 {
+		t.Errorf("c changed after a failed Assign. got=%s", val.Inspect())
+	}
+}
+
+func TestLexicalScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"x := 1; f := fn(x) { x }; f(2)", 2},
+		{"x := 1; f := fn() { x := 2; x }; f() + x", 3},
+		{"x := 1; f := fn() { x := x + 1; x }; f()", 2},
+		{"x := 1; f := fn() { x = 5 }; f(); x", 5},
+		{`
+		counter := fn() {
+			count := 0
+			return fn() { count++; count }
+		}
+		a := counter()
+		b := counter()
+		a(); a(); b()
+		a()`, 3},
+		{`
+		fn accumulator(total) {
+			return fn(n) { total += n }
+		}
+		add := accumulator(10)
+		add(5)
+		add(5)`, 20},
+		{"x := 1; if true { x := 2; if true { x = 3 } }; x", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
 }
 
 func TestAssignExpression(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected int64
 	}{
 		{"a := 5; a = 25; a;", 25},
 		{"a := 5; b := a; a = a + b + 5; a;", 15},
@@ -172,19 +447,81 @@ func TestAssignExpression(t *testing.T) {
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testNumberObject(t, evaluated, tt.expected)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a := [1, 2, 3]; a[0] = 5; a", "[5, 2, 3]"},
+		{"a := [1, 2, 3]; a[-1] = 5; a", "[1, 2, 5]"},
+		{"a := [1, 2, 3]; a[1] += 10; a", "[1, 12, 3]"},
+		{"a := [1, 2, 3]; a[2]++; a", "[1, 2, 4]"},
+		{"a := [1, 2, 3]; b := a; b[0] = 0; a", "[0, 2, 3]"},
+		{"a := [[1], [2]]; a[1][0] = 3; a", "[[1], [3]]"},
+		{"i := 0; a := [1, 2]; a[i] = i = 1; a", "[1, 2]"},
+		{`o := {a: 1}; o["a"] = 2; o`, `{"a": 2}`},
+		{`o := {a: 1}; o["b"] = 2; o`, `{"a": 1, "b": 2}`},
+		{`o := {a: 1}; o.a = 2; o.b = 3; o`, `{"a": 2, "b": 3}`},
+		{`o := {n: 1}; o.n *= 5; o.n--; o`, `{"n": 4}`},
+		{`o := {a: {b: [1]}}; o.a.b[0] = 2; o`, `{"a": {"b": [2]}}`},
+		{`o := {}; o[1] = "x"; o[1.0]`, `"x"`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"a := 5; a += 2; a", 7},
+		{"a := 5; a -= 2; a", 3},
+		{"a := 5; a *= 2; a", 10},
+		{"a := 5; a /= 2; a", 2.5},
+		{"a := 5; a %= 2; a", 1},
+		{"a := 5; a += 2", 7},
+		{"a := 1; a += a += 1; a", 3},
+		{`s := "a"; s += "b"; s`, "ab"},
+		{"a := 5; a++; a", 6},
+		{"a := 5; a--; a", 4},
+		{"a := 1.5; a++; a", 2.5},
+		{"a := 0; f := fn() { a++; }; f(); f(); a", 2},
+		{"n := 0; for i := 0; i < 5; i++ { n += i; }; n", 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testNumberObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		}
 	}
 }
 
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected int64
 	}{
 		{"return 10;", 10},
 		{"return 10; 9;", 10},
 		{"return 2 * 5; 9;", 10},
 		{"9; return 2 * 5; 9;", 10},
+		{"return 10\n9", 10},
 		{`if 10 > 1 {
 			if 10 > 1 {
 				return 10;
@@ -195,8 +532,37 @@ func TestReturnStatements(t *testing.T) {
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testNumberObject(t, evaluated, tt.expected)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestProgramWithoutSemicolons(t *testing.T) {
+	input := `
+	total := 0
+	numbers := [
+		1,
+		2,
+		3,
+	]
+
+	sum := fn(values) {
+		result := 0
+		for v in values {
+			result = result + v
+		}
+		return result
+	}
+
+	if sum(numbers) > 5 {
+		total = sum(numbers)
+	} else {
+		return
 	}
+
+	total`
+	testIntegerObject(t, testEval(input), 6)
+
+	testNilObject(t, testEval("fn() {\n\treturn\n}()"))
 }
 
 func TestFunctionObject(t *testing.T) {
@@ -235,8 +601,8 @@ func TestArrayLiterals(t *testing.T) {
 		t.Fatalf("array has wrong number of elements (%d)", len(array.Elements))
 	}
 
-	testNumberObject(t, array.Elements[0], 1)
-	testNumberObject(t, array.Elements[1], 5)
+	testIntegerObject(t, array.Elements[0], 1)
+	testIntegerObject(t, array.Elements[1], 5)
 }
 
 func TestArrayIndexExpressions(t *testing.T) {
@@ -253,23 +619,294 @@ func TestArrayIndexExpressions(t *testing.T) {
 		{"myArray := [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
 		{"myArray := [1, 2, 3]; i := myArray[0]; myArray[i]", 2},
 		{"[1, 2, 3][3]", nil},
-		{"[1, 2, 3][3]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", nil},
+		{"[1, 2, 3][4 / 2]", 3},
+		{"[1, 2, 3][100000000000000000000]", nil},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
 		integer, ok := tt.expected.(int)
 		if ok {
-			testNumberObject(t, evaluated, float64(integer))
+			testIntegerObject(t, evaluated, int64(integer))
 		} else {
 			testNilObject(t, evaluated)
 		}
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][1:100]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4][-100:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[1, 2, 3, 4][::2]", "[1, 3]"},
+		{"[1, 2, 3, 4][1::2]", "[2, 4]"},
+		{"[1, 2, 3, 4][::-1]", "[4, 3, 2, 1]"},
+		{"[1, 2, 3, 4][2::-1]", "[3, 2, 1]"},
+		{"[1, 2, 3, 4][:0:-1]", "[4, 3, 2]"},
+		{"[1, 2, 3, 4][-1:-3:-1]", "[4, 3]"},
+		{"[1, 2, 3][1::9223372036854775807]", "[2]"},
+		{"[1, 2, 3][::100000000000000000000]", "[1]"},
+		{"[1, 2, 3][1::-9223372036854775808]", "[2]"},
+		{"[1, 2, 3][::-100000000000000000000]", "[3]"},
+		{"[][1:2]", "[]"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[:-1]`, "hell"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[10:]`, ""},
+		{`"abc"[1::100000000000000000000]`, "b"},
+		{`""[::-9223372036854775808]`, ""},
+		{`"hello"[1]`, "e"},
+		{`"hello"[-1]`, "o"},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[1:3]`, "él"},
+		{`"日本語"[::-1]`, "語本日"},
+		{`"日本語"[-1]`, "語"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch evaluated := evaluated.(type) {
+		case *Array:
+			if evaluated.Inspect() != tt.expected {
+				t.Errorf("wrong slice for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+			}
+		default:
+			testStringObject(t, evaluated, tt.expected.(string))
+		}
+	}
+}
+
+func TestSlicesAreCopies(t *testing.T) {
+	input := `a := [1, 2, 3]; b := a[:]; b.push(4); len(a)`
+	testIntegerObject(t, testEval(input), 3)
+}
+
+func TestSpreadElements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a := [1, 2]; b := [3]; [...a, ...b]", "[1, 2, 3]"},
+		{"a := [2, 3]; [1, ...a, 4]", "[1, 2, 3, 4]"},
+		{"[...[]]", "[]"},
+		{"add := fn(a, b, c) { [a, b, c] }; add(...[1, 2, 3])", "[1, 2, 3]"},
+		{"add := fn(a, b, c) { [a, b, c] }; args := [2, 3]; add(1, ...args)", "[1, 2, 3]"},
+		{"len(...[[1, 2]])", "2"},
+		{"d := {a: 1, b: 2}; o := {b: 3}; {...d, ...o}", `{"a": 1, "b": 3}`},
+		{"d := {a: 1}; {...d, a: 2, c: 3}", `{"a": 2, "c": 3}`},
+		{"d := {a: 1}; {a: 2, ...d}", `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSpreadCopiesObject(t *testing.T) {
+	input := `a := [1]; b := [...a]; b.push(2); len(a)`
+	testIntegerObject(t, testEval(input), 1)
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 - 3,
+		4: 4,
+		true: 5,
+		false: 6,
+	}`
+
+	evaluated := testEval(input)
+	hash, ok := evaluated.(*Hash)
+	if !ok {
+		t.Fatalf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := []struct {
+		key   Hashable
+		value int64
+	}{
+		{&String{Value: "one"}, 1},
+		{&String{Value: "two"}, 2},
+		{&String{Value: "three"}, 3},
+		{&Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
+	}
+
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash has wrong number of pairs. got=%d", len(hash.Pairs))
+	}
+
+	for i, tt := range expected {
+		if hash.Keys[i] != tt.key.HashKey() {
+			t.Errorf("hash.Keys[%d] is not %s", i, tt.key.Inspect())
+		}
+		value, ok := hash.Get(tt.key)
+		if !ok {
+			t.Errorf("no pair for given key %s", tt.key.Inspect())
+			continue
+		}
+		testIntegerObject(t, value, tt.value)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`key := "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{-0: 5}[0]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`{a: {b: 5}}["a"]["b"]`, 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
+func TestNumberHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"{1: 1}[1.0]", 1},
+		{"{2.0: 2}[2]", 2},
+		{"{-0.0: 3}[0]", 3},
+		{"{100000000000000000000: 4}[100000000000000000000]", 4},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashInspect(t *testing.T) {
+	input := `{b: 1, "a": [true], 2: nil, b: 3}`
+
+	evaluated := testEval(input)
+	if evaluated.Inspect() != `{"b": 3, "a": [true], 2: nil}` {
+		t.Errorf("hash.Inspect() wrong. got=%q", evaluated.Inspect())
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{name: 5}.name`, 5},
+		{`user := {name: 5}; user.name`, 5},
+		{`user := {name: 5}; user.age`, nil},
+		{`a := {b: {c: 5}}; a.b.c`, 5},
+		{`user := {double: fn(x) { x * 2 }}; user.double(5)`, 10},
+		{`user := {age: 5, older: fn(u) { u.age + 1 }}; user.older(user)`, 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
+func TestStringMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc".upper()`, "ABC"},
+		{`"ABC".lower()`, "abc"},
+		{`"  abc ".trim()`, "abc"},
+		{`"abc".contains("b")`, true},
+		{`"abc".contains("d")`, false},
+		{`s := "a,b"; s.split(",")[1]`, "b"},
+		{`len("a,b,c".split(","))`, 3},
+		{`upper := "abc".upper; upper()`, "ABC"},
+		{`len("héllo".bytes())`, 6},
+		{`"é".bytes()[1]`, 169},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		}
+	}
+}
+
+func TestArrayMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`arr := [1, 2, 3]; arr.push(4); arr[3]`, 4},
+		{`arr := [1]; arr.push(2, 3); len(arr)`, 3},
+		{`arr := [1, 2, 3]; arr.pop()`, 3},
+		{`arr := [1, 2, 3]; arr.pop(); len(arr)`, 2},
+		{`[].pop()`, nil},
+		{`[1, "a", true].contains("a")`, true},
+		{`[1, "a", true].contains(2)`, false},
+		{`[1, "a", true].join(", ")`, "1, a, true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		default:
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
 func TestFunctionApplication(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected int64
 	}{
 		{"identity := fn(x) { x; }; identity(5);", 5},
 		{"identity := fn(x) { return x; }; identity(5);", 5},
@@ -279,7 +916,107 @@ func TestFunctionApplication(t *testing.T) {
 		{"fn(x) { x; }(5)", 5},
 	}
 	for _, tt := range tests {
-		testNumberObject(t, testEval(tt.input), tt.expected)
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRestParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f := fn(...rest) { rest }; f()", "[]"},
+		{"f := fn(...rest) { rest }; f(1, 2, 3)", "[1, 2, 3]"},
+		{"f := fn(first, ...rest) { [first, rest] }; f(1)", "[1, []]"},
+		{"f := fn(first, ...rest) { [first, rest] }; f(1, 2, 3)", "[1, [2, 3]]"},
+		{"f := fn(a, b = 2, ...rest) { [a, b, rest] }; f(1)", "[1, 2, []]"},
+		{"f := fn(a, b = 2, ...rest) { [a, b, rest] }; f(1, 3, 5)", "[1, 3, [5]]"},
+		{"f := fn(...rest) { rest }; f(...[1, 2], 3)", "[1, 2, 3]"},
+		{`
+		sum := fn(...xs) {
+			total := 0
+			for x in xs { total += x }
+			return total
+		}
+		sum(1, 2, 3, 4)`, "10"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestDefaultParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"add := fn(a, b = 10) { a + b }; add(1)", 11},
+		{"add := fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"f := fn(a = 1, b = 2) { a * 10 + b }; f()", 12},
+		{"f := fn(a, b = a * 2) { b }; f(4)", 8},
+		{"n := 1; f := fn(a = n) { a }; n = 5; f()", 5},
+		{"f := fn(a = []) { a.push(1); len(a) }; f(); f()", 1},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn add(a, b) { a + b }; add(1, 2)", 3},
+		{"x := add(1, 2); fn add(a, b) { a + b }; x", 3},
+		{`
+		fn isEven(n) { if n == 0 { return true }; return isOdd(n - 1) }
+		fn isOdd(n) { if n == 0 { return false }; return isEven(n - 1) }
+		if isOdd(7) { 1 } else { 0 }`, 1},
+		{"fn fib(n) { if n < 2 { return n }; return fib(n - 1) + fib(n - 2) }; fib(10)", 55},
+		{`
+		fn outer() {
+			return inner() * 2
+			fn inner() { 21 }
+		}
+		outer()`, 42},
+		{"n := 0; for i in [1, 2, 3] { fn double(x) { x * 2 }; n += double(i) }; n", 12},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionStatementStackTrace(t *testing.T) {
+	errObj, ok := testEval("check()\nfn check() { 1 + true }").(*Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	if len(errObj.Stack) != 1 || errObj.Stack[0].String() != "check called at 1:1" {
+		t.Errorf("wrong stack. expected=[check called at 1:1], got=%v", errObj.Stack)
+	}
+}
+
+func TestFunctionInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(a, b = 1) { a + b }; add", "fn add(a, b = 1) {\n(a + b)\n}"},
+		{"fn(a) { a }", "fn (a) {\na\n}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
 	}
 }
 
@@ -289,22 +1026,35 @@ func TestClosures(t *testing.T) {
      fn(y) { x + y };
 };
 addTwo := newAdder(2); addTwo(2);`
-	testNumberObject(t, testEval(input), 4)
+	testIntegerObject(t, testEval(input), 4)
+}
+
+func TestClosureAssignment(t *testing.T) {
+	input := `
+	count := 0;
+	increment := fn() { count = count + 1; };
+	increment(); increment();
+	count;`
+	testIntegerObject(t, testEval(input), 2)
 }
 
 func TestBuiltinFunctions(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected float64
+		expected int64
 	}{
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
+		{`len("日本語")`, 3},
+		{`len({})`, 0},
+		{`len({a: 1, b: 2})`, 2},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testNumberObject(t, evaluated, tt.expected)
+		testIntegerObject(t, evaluated, tt.expected)
 	}
 }
 
@@ -315,15 +1065,15 @@ func TestErrorHandling(t *testing.T) {
 	}{
 		{
 			"5 + true;",
-			"type mismatch: number + boolean",
+			"type mismatch: integer + boolean",
 		},
 		{
 			`5 + "a";`,
-			"type mismatch: number + string",
+			"type mismatch: integer + string",
 		},
 		{
 			"5 + true; 5;",
-			"type mismatch: number + boolean",
+			"type mismatch: integer + boolean",
 		},
 		{
 			"-true",
@@ -331,7 +1081,7 @@ func TestErrorHandling(t *testing.T) {
 		},
 		{
 			"!10",
-			"invalid operation: operator ! is not defined for 10 (number)",
+			"invalid operation: operator ! is not defined for 10 (integer)",
 		},
 		{
 			`"a" - "b"`,
@@ -351,7 +1101,7 @@ func TestErrorHandling(t *testing.T) {
 		},
 		{
 			"if 10 { true + false; }",
-			"type mismatch: non-boolean condition 10 (number) in if statement",
+			"type mismatch: non-boolean condition 10 (integer) in if statement",
 		},
 		{
 			`if 10 > 1 {
@@ -362,6 +1112,22 @@ func TestErrorHandling(t *testing.T) {
 			}`,
 			"invalid operation: operator + is not defined for true (boolean)",
 		},
+		{
+			"for 1 { }",
+			"type mismatch: non-boolean condition 1 (integer) in for statement",
+		},
+		{
+			"for v in 5 { }",
+			"invalid operation: can not range over 5 (integer)",
+		},
+		{
+			"for { true + false; }",
+			"invalid operation: operator + is not defined for true (boolean)",
+		},
+		{
+			"for i := 0; i < 1; i = i + 1 { }; i",
+			"undeclared name: i",
+		},
 		{
 			"foobar",
 			"undeclared name: foobar",
@@ -370,9 +1136,61 @@ func TestErrorHandling(t *testing.T) {
 			"foobar = 5",
 			"undeclared name: foobar",
 		},
+		{
+			"foobar += 5",
+			"undeclared name: foobar",
+		},
+		{
+			"foobar++",
+			"undeclared name: foobar",
+		},
+		{
+			`s := "a"; s -= 1`,
+			"type mismatch: string - integer",
+		},
+		{
+			"a := [1, 2, 3]; a[3] = 4",
+			"invalid argument: index 3 out of range for array of length 3",
+		},
+		{
+			"a := [1, 2, 3]; a[-4] = 4",
+			"invalid argument: index -4 out of range for array of length 3",
+		},
+		{
+			"a := [1, 2, 3]; a[2] = a.pop()",
+			"invalid argument: index 2 out of range for array of length 2",
+		},
+		{
+			`a := [1]; a["x"] = 4`,
+			"type mismatch: non-number \"x\" (string) can not index an array",
+		},
+		{
+			`s := "abc"; s[0] = "x"`,
+			"invalid operation: can not assign to an index of \"abc\" (string)",
+		},
+		{
+			`s := "abc"; s.x = 1`,
+			"invalid operation: can not assign to member x of \"abc\" (string)",
+		},
+		{
+			`o := {}; o[[1]] = 1`,
+			"invalid argument: [1] (array) can not be used as an object key",
+		},
+		{
+			"o := {}; o.count++",
+			"invalid operation: operator ++ is not defined for nil (nil)",
+		},
+		{
+			"a[0] = 1",
+			"undeclared name: a",
+		},
+		{
+			`s := "a"; s++`,
+			"invalid operation: operator ++ is not defined for \"a\" (string)",
+		},
 		{
 			"a := 5; 5()",
-			"invalid operation: can not call non-function (number)",
+			"invalid operation: can not call non-function (integer)",
 		},
 		{
 			"a := 5; a := 6",
@@ -380,7 +1198,7 @@ func TestErrorHandling(t *testing.T) {
 		},
 		{
 			`len(1)`,
-			"invalid argument: 1 (number) for len",
+			"invalid argument: 1 (integer) for len",
 		},
 		{
 			`len("one", "two")`,
@@ -390,6 +1208,86 @@ func TestErrorHandling(t *testing.T) {
 			`[1, 2, 3]["hey"]`,
 			"type mismatch: non-number \"hey\" (string) can not index an array",
 		},
+		{
+			`{"name": "Dara"}[fn(x) { x }];`,
+			"invalid argument: fn (x) {\nx\n} (fn) can not be used as an object key",
+		},
+		{
+			`{[1]: 2}`,
+			"invalid argument: [1] (array) can not be used as an object key",
+		},
+		{
+			`"abc"[nil]`,
+			"type mismatch: non-number nil (nil) can not index a string",
+		},
+		{
+			`[1, 2][0:"a"]`,
+			"type mismatch: non-number \"a\" (string) can not be used as a slice bound",
+		},
+		{
+			`[1, 2][::0]`,
+			"invalid argument: slice step can not be zero",
+		},
+		{
+			`{a: 1}[0:1]`,
+			"invalid operation: can not slice {\"a\": 1} (object)",
+		},
+		{
+			`[...1]`,
+			"invalid operation: can not spread 1 (integer) into a list",
+		},
+		{
+			`len(...{a: 1})`,
+			"invalid operation: can not spread {\"a\": 1} (object) into a list",
+		},
+		{
+			`{...[1]}`,
+			"invalid operation: can not spread [1] (array) into an object",
+		},
+		{
+			`5[0]`,
+			"invalid operation: can not index 5 (integer)",
+		},
+		{
+			`"abc".reverse()`,
+			"invalid operation: \"abc\" (string) has no member reverse",
+		},
+		{
+			`[1, 2].length`,
+			"invalid operation: [1, 2] (array) has no member length",
+		},
+		{
+			`user := nil; user.name`,
+			"invalid operation: nil (nil) has no member name",
+		},
+		{
+			`"abc".upper(1)`,
+			"invalid operation: too many arguments for upper (expected 0, found 1)",
+		},
+		{
+			`"abc".split()`,
+			"invalid operation: not enough arguments for split (expected 1, found 0)",
+		},
+		{
+			`[1, 2].join(1)`,
+			"invalid argument: 1 (integer) for join",
+		},
+		{
+			`"a ${b} c"`,
+			"undeclared name: b",
+		},
+		{
+			"5 % 0",
+			"invalid operation: division by zero",
+		},
+		{
+			"[1, 2, 3][1.7]",
+			"invalid argument: index 1.7 (number) must be an integer",
+		},
+		{
+			`"abc"[0.5:]`,
+			"invalid argument: index 0.5 (number) must be an integer",
+		},
 	}
 
 	for _, tt := range tests {
@@ -405,6 +1303,94 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1 + "a"`, `1:1: type mismatch: integer + string`},
+		{"x := 1\ny := -true", "2:6: invalid operation: operator - is not defined for true (boolean)"},
+		{"x := 1\nlen(x, x)", "2:1: invalid operation: too many arguments for len (expected 1, found 2)"},
+		{"f := fn(a) {\n  return a.b\n}\nf(1)", "2:10: invalid operation: 1 (integer) has no member b"},
+		{"y", "1:1: undeclared name: y"},
+		{"add := fn(a, b) { a + b }\nx := add(1)", "2:6: invalid operation: not enough arguments for add (expected 2, found 1)"},
+		{"fn(a = 1) { a }(1, 2)", "1:1: invalid operation: too many arguments for fn (expected at most 1, found 2)"},
+		{"f := fn(a, b = 1) { a }\nf()", "2:1: invalid operation: not enough arguments for f (expected at least 1, found 0)"},
+		{"f := fn(a, b) { a }\nf(1, 2, 3)", "2:1: invalid operation: too many arguments for f (expected 2, found 3)"},
+		{"f := fn(a = b) { a }\nf()", "1:13: undeclared name: b"},
+		{"x := 1\nfn f() {}\nfn f() {}", "3:4: invalid operation: can not redeclare f"},
+		{"if true { x := 1 }\nx", "2:1: undeclared name: x"},
+		{"f := fn(x) { x := 1 }\nf(2)", "1:14: invalid operation: can not redeclare x"},
+		{"f := fn(a, ...rest) { a }\nf()", "2:1: invalid operation: not enough arguments for f (expected at least 1, found 0)"},
+		{"const x = 1\nx = 2", "2:1: invalid operation: can not assign to constant x"},
+		{"const x = 1\nx += 2", "2:1: invalid operation: can not assign to constant x"},
+		{"const x = 1\nif true {\n  x++\n}", "3:3: invalid operation: can not assign to constant x"},
+		{"const x = 1\nf := fn() { x = 2 }\nf()", "2:13: invalid operation: can not assign to constant x"},
+		{"x := 1\nconst x = 2", "2:1: invalid operation: can not redeclare x"},
+		{"const x = 1\nx := 2", "2:1: invalid operation: can not redeclare x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errObj.Error())
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `add := fn(a, b) { a + b }
+sum := fn(xs) {
+  total := 0
+  for x in xs { total = add(total, x) }
+  return total
+}
+fn() { sum([1, "two"]) }()`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := []string{
+		"add called at 4:25",
+		"sum called at 7:8",
+		"fn called at 7:1",
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong number of frames. expected=%d, got=%d (%v)",
+			len(expected), len(errObj.Stack), errObj.Stack)
+	}
+	for i, frame := range errObj.Stack {
+		if frame.String() != expected[i] {
+			t.Errorf("frames[%d] wrong. expected=%q, got=%q", i, expected[i], frame.String())
+		}
+	}
+}
+
+func TestArityErrorStackTrace(t *testing.T) {
+	// The function called with the wrong arguments never ran, so only the
+	// calls that led to it are in the trace.
+	input := `add := fn(a, b) { a + b }
+outer := fn() { add(1) }
+outer()`
+
+	errObj, ok := testEval(input).(*Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	if len(errObj.Stack) != 1 || errObj.Stack[0].String() != "outer called at 3:1" {
+		t.Errorf("wrong stack. expected=[outer called at 3:1], got=%v", errObj.Stack)
+	}
+}
+
 func testNumberObject(t *testing.T, obj Object, expected float64) bool {
 	result, ok := obj.(*Number)
 	if !ok {
@@ -418,6 +1404,19 @@ func testNumberObject(t *testing.T, obj Object, expected float64) bool {
 	return true
 }
 
+func testIntegerObject(t *testing.T, obj Object, expected int64) bool {
+	result, ok := obj.(*Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Big != nil || result.Value != expected {
+		t.Errorf("object has wrong value. got=%s, want=%d", result.Inspect(), expected)
+		return false
+	}
+	return true
+}
+
 func testNilObject(t *testing.T, obj Object) bool {
 	if obj != NIL {
 		t.Errorf("object is not NIL. got=%T (%+v)", obj, obj)
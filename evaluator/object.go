@@ -3,7 +3,12 @@ package evaluator
 import (
 	"bytes"
 	"dara/ast"
+	"dara/token"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -11,6 +16,7 @@ type ObjectType string
 
 const (
 	NUMBER_OBJ       ObjectType = "number"
+	INTEGER_OBJ      ObjectType = "integer"
 	BOOLEAN_OBJ      ObjectType = "boolean"
 	STRING_OBJ       ObjectType = "string"
 	NIL_OBJ          ObjectType = "nil"
@@ -19,6 +25,9 @@ const (
 	FUNCTION_OBJ     ObjectType = "fn"
 	BUILTIN_OBJ      ObjectType = "builtin"
 	ARRAY_OBJ        ObjectType = "array"
+	HASH_OBJ         ObjectType = "object"
+	BREAK_OBJ        ObjectType = "break"
+	CONTINUE_OBJ     ObjectType = "continue"
 )
 
 type Object interface {
@@ -32,6 +41,63 @@ type Number struct {
 
 func (n *Number) Type() ObjectType { return NUMBER_OBJ }
 func (n *Number) Inspect() string  { return fmt.Sprintf("%v", n.Value) }
+func (n *Number) HashKey() HashKey {
+	// A whole number indexes the same pair as the equal Integer, as the two
+	// compare equal.
+	if n.Value == math.Trunc(n.Value) && !math.IsInf(n.Value, 0) {
+		i, _ := big.NewFloat(n.Value).Int(nil)
+		return newInteger(i).HashKey()
+	}
+	return HashKey{Type: n.Type(), Value: math.Float64bits(n.Value)}
+}
+
+// Integer is a whole number. It is stored in Value, unless it is too large for
+// an int64, in which case it is stored in Big instead.
+type Integer struct {
+	Value int64
+	Big   *big.Int
+}
+
+// newInteger returns an Integer for i, using an int64 whenever it fits.
+func newInteger(i *big.Int) *Integer {
+	if i.IsInt64() {
+		return &Integer{Value: i.Int64()}
+	}
+	return &Integer{Big: i}
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+	return strconv.FormatInt(i.Value, 10)
+}
+func (i *Integer) HashKey() HashKey {
+	if i.Big != nil {
+		h := fnv.New64a()
+		h.Write([]byte(i.Big.String()))
+		return HashKey{Type: i.Type(), Value: h.Sum64()}
+	}
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+// BigInt returns the value of i as a big.Int.
+func (i *Integer) BigInt() *big.Int {
+	if i.Big != nil {
+		return i.Big
+	}
+	return big.NewInt(i.Value)
+}
+
+// Float returns the value of i as the nearest float64.
+func (i *Integer) Float() float64 {
+	if i.Big != nil {
+		f, _ := new(big.Float).SetInt(i.Big).Float64()
+		return f
+	}
+	return float64(i.Value)
+}
 
 type Boolean struct {
 	Value bool
@@ -39,6 +105,13 @@ type Boolean struct {
 
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
 
 type String struct {
 	Value string
@@ -46,6 +119,11 @@ type String struct {
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return `"` + s.Value + `"` }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
 
 type Nil struct{}
 
@@ -59,15 +137,50 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
 type Error struct {
 	Message string
+	Pos     token.Position // where the error was raised, if known
+	Stack   []Frame        // calls active when the error was raised, innermost first
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return e.Message }
 
+// Error formats the error as `file:line:col: message`.
+func (e *Error) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Message
+	}
+	return e.Pos.String() + ": " + e.Message
+}
+
+// Frame is a call to a Dara function that was active when an error was raised.
+type Frame struct {
+	Function string         // name the function was declared with, if any
+	Pos      token.Position // position of the call
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "fn"
+	}
+	return fmt.Sprintf("%s called at %s", name, f.Pos)
+}
+
 type Function struct {
-	Parameters []*ast.Identifier
+	Name       string // set when the function is declared with a name or bound with `:=`
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -81,7 +194,7 @@ func (f *Function) Inspect() string {
 		params[i] = p.String()
 	}
 
-	out.WriteString("fn (")
+	out.WriteString("fn " + f.Name + "(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") {\n")
 	out.WriteString(f.Body.String())
@@ -92,6 +205,8 @@ func (f *Function) Inspect() string {
 
 type BuiltinFunction func(args ...Object) Object
 
+type BuiltinMethod func(receiver Object, args ...Object) Object
+
 type Builtin struct {
 	Fn BuiltinFunction
 }
@@ -118,3 +233,60 @@ func (a *Array) Inspect() string {
 
 	return out.String()
 }
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by every object that can be used as a hash key.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash stores its pairs by key, and remembers the order keys were first added
+// in so that inspecting a hash is deterministic.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, len(h.Keys))
+	for i, key := range h.Keys {
+		pair := h.Pairs[key]
+		pairs[i] = pair.Key.Inspect() + ": " + pair.Value.Inspect()
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
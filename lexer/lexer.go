@@ -4,50 +4,145 @@ import (
 	"dara/token"
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-// Lexer allows you to extract tokens from a dara input string.
+// DiagnosticKind identifies the kind of problem a `Diagnostic` reports.
+type DiagnosticKind int
+
+const (
+	UnterminatedString DiagnosticKind = iota
+	UnterminatedComment
+	IllegalCharacter
+	InvalidEscape
+	MalformedNumber
+)
+
+// Diagnostic is a problem found while scanning the input.
+type Diagnostic struct {
+	Kind DiagnosticKind
+	Pos  token.Position
+	Msg  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Msg)
+}
+
+// Lexer allows you to extract tokens from a dara input string. The input is
+// read as UTF-8, one code point at a time.
 type Lexer struct {
-	input    string
-	line     int
-	position int
-	ch       byte
+	filename     string
+	input        string
+	line         int
+	lineStart    int
+	position     int  // offset of ch
+	readPosition int  // offset of the character after ch
+	ch           rune // current character, or 0 at the end of the input
+
+	// insertSemi is set when the last token could end a statement, in which
+	// case the next newline is turned into a semicolon.
+	insertSemi bool
+
+	// templates holds the interpolated strings the lexer is inside of,
+	// innermost last.
+	templates []template
+
+	errors []Diagnostic
+}
+
+// template tracks an interpolated string while the expressions inside it are
+// lexed.
+type template struct {
+	pos   token.Position // opening quote
+	quote rune
+	depth int // braces opened inside the current `${ }`
 }
 
 // New returns a primed `Lexer`.
 func New(input string) *Lexer {
-	l := &Lexer{input: input, line: 1, position: -1}
+	return NewFile("", input)
+}
+
+// NewFile returns a primed `Lexer` for the contents of a file. The filename is
+// recorded in the position of every token.
+func NewFile(filename, input string) *Lexer {
+	l := &Lexer{filename: filename, input: input, line: 1}
 	l.advance()
 	return l
 }
 
-func (l *Lexer) Position() int {
-	return l.line
+// Position returns the current position of the lexer in its input.
+func (l *Lexer) Position() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.position - l.lineStart + 1,
+	}
+}
+
+// Errors returns the diagnostics reported so far, in the order they were
+// found.
+func (l *Lexer) Errors() []Diagnostic {
+	return l.errors
 }
 
 // Scan scans the entire input and returns all of the tokens.
 func (l *Lexer) Scan() []*token.Token {
 	var tokens []*token.Token
 
-	for !l.isAtEnd() {
-		tokens = append(tokens, l.NextToken())
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens
+		}
 	}
-	tokens = append(tokens, l.NextToken())
-
-	return tokens
 }
 
 // NextToken scans through and returns individual tokens.
+//
+// Like Go, semicolons are inserted automatically: when a line ends after a
+// token that could end a statement (an identifier, a literal, one of the
+// keywords `return`, `break` or `continue`, `++`, `--` or a closing bracket),
+// the lexer returns a SEMICOLON with the literal "\n" in place of the newline.
 func (l *Lexer) NextToken() *token.Token {
-	var tok *token.Token
-
 	l.skipWhitespace()
 
+	pos := l.Position()
+
+	if l.insertSemi && l.atLineEnd() {
+		l.insertSemi = false
+		tok := token.New(token.SEMICOLON, "\n")
+		tok.Pos, tok.End = pos, pos
+		return tok
+	}
+
+	tok := l.scanToken()
+
+	tok.Pos, tok.End = pos, l.Position()
+	if tok.Type == token.EOF {
+		tok.End = pos
+	}
+
+	// Comments act like whitespace, so they leave insertSemi alone.
+	if tok.Type != token.COMMENT {
+		l.insertSemi = endsStatement(tok.Type)
+	}
+
+	return tok
+}
+
+func (l *Lexer) scanToken() *token.Token {
+	var tok *token.Token
+
 	switch l.ch {
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.incDec('-', token.DEC, token.MINUS_ASSIGN, token.MINUS)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.lookAhead('=', token.ASTERISK_ASSIGN, token.ASTERISK)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case '(':
@@ -56,18 +151,36 @@ func (l *Lexer) NextToken() *token.Token {
 		tok = newToken(token.RPAREN, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case '.':
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			l.advance()
+			l.advance()
+			tok = token.New(token.SPREAD, "...")
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.incDec('+', token.INC, token.PLUS_ASSIGN, token.PLUS)
 	case '{':
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1].depth++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		if len(l.templates) > 0 {
+			t := &l.templates[len(l.templates)-1]
+			if t.depth == 0 {
+				return l.templateContinue()
+			}
+			t.depth--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case '%':
-		tok = newToken(token.MOD, l.ch)
+		tok = l.lookAhead('=', token.MOD_ASSIGN, token.MOD)
 
 	case '<':
 		tok = l.lookAhead('=', token.LT_EQ, token.LT)
@@ -82,21 +195,21 @@ func (l *Lexer) NextToken() *token.Token {
 			l.advance()
 			tok = token.New(token.AND, "&&")
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = l.illegal()
 		}
 	case '|':
 		if l.peek() == '|' {
 			l.advance()
 			tok = token.New(token.OR, "||")
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = l.illegal()
 		}
 	case ':':
 		if l.peek() == '=' {
 			l.advance()
 			tok = token.New(token.DECLARE, ":=")
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = newToken(token.COLON, l.ch)
 		}
 
 	case '/':
@@ -106,24 +219,31 @@ func (l *Lexer) NextToken() *token.Token {
 		case '*':
 			return token.New(token.COMMENT, l.blockComment())
 		default:
-			tok = newToken(token.SLASH, l.ch)
+			tok = l.lookAhead('=', token.SLASH_ASSIGN, token.SLASH)
 		}
 
-	case '"':
-		return token.New(token.STRING, l.string('"'))
-	case '\'':
-		return token.New(token.STRING, l.string('\''))
+	case '"', '\'':
+		if strings.HasPrefix(l.input[l.position:], `"""`) {
+			return l.heredoc()
+		}
+		return l.string()
+	case '`':
+		return l.rawString()
 
 	case 0:
+		for _, t := range l.templates {
+			l.error(UnterminatedString, t.pos, "string literal not terminated")
+		}
+		l.templates = nil
 		tok = token.New(token.EOF, "")
 	default:
 		switch {
-		case isAlpha(l.ch):
+		case isLetter(l.ch):
 			return l.readIdentifier()
 		case isDigit(l.ch):
 			return l.readNumber()
 		default:
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = l.illegal()
 		}
 	}
 
@@ -132,31 +252,43 @@ func (l *Lexer) NextToken() *token.Token {
 }
 
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		if l.ch == '\n' {
-			l.line++
-		}
+	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' && !l.insertSemi || l.ch == '\r' {
 		l.advance()
 	}
 }
 
 func (l *Lexer) advance() {
-	if l.position+1 >= len(l.input) {
+	if l.ch == '\n' {
+		l.line++
+		l.lineStart = l.readPosition
+	}
+	l.position = l.readPosition
+	if l.position >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.position+1]
+		return
 	}
-	l.position++
+	ch, width := decodeRune(l.input[l.position:])
+	l.ch = ch
+	l.readPosition += width
 }
 
-func (l *Lexer) peek() byte {
-	if l.position+1 >= len(l.input) {
+func (l *Lexer) peek() rune {
+	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.position+1]
+	ch, _ := decodeRune(l.input[l.readPosition:])
+	return ch
+}
+
+// decodeRune decodes the first character in s, taking the fast path for ASCII.
+func decodeRune(s string) (rune, int) {
+	if s[0] < utf8.RuneSelf {
+		return rune(s[0]), 1
+	}
+	return utf8.DecodeRuneInString(s)
 }
 
-func (l *Lexer) lookAhead(check byte, a, b token.TokenType) *token.Token {
+func (l *Lexer) lookAhead(check rune, a, b token.TokenType) *token.Token {
 	if l.peek() == check {
 		ch := l.ch
 		l.advance()
@@ -165,50 +297,332 @@ func (l *Lexer) lookAhead(check byte, a, b token.TokenType) *token.Token {
 	return newToken(b, l.ch)
 }
 
+// incDec scans an operator that may be doubled, like `++`, or followed by `=`,
+// like `+=`.
+func (l *Lexer) incDec(ch rune, double, assign, single token.TokenType) *token.Token {
+	switch l.peek() {
+	case ch:
+		l.advance()
+		return token.New(double, string(ch)+string(ch))
+	case '=':
+		l.advance()
+		return token.New(assign, string(ch)+"=")
+	}
+	return newToken(single, l.ch)
+}
+
 func (l *Lexer) readIdentifier() *token.Token {
 	start := l.position
-	for isAlphaNumeric(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch >= utf8.RuneSelf && unicode.IsDigit(l.ch) {
 		l.advance()
 	}
 	literal := l.input[start:l.position]
 	return token.New(token.LookupIdent(literal), literal)
 }
 
+// readNumber scans a number literal: a decimal like `1_000`, `1.5` or `2.5e-3`,
+// or an integer with a `0x`, `0b` or `0o` prefix. The literal is returned as
+// written, and any problem with it is reported as a diagnostic.
 func (l *Lexer) readNumber() *token.Token {
+	pos := l.Position()
 	start := l.position
-	for isDigit(l.ch) {
-		l.advance()
+
+	if l.ch == '0' {
+		if base, name := numberBase(l.peek()); base != 0 {
+			l.advance()
+			l.advance()
+			// Every digit is scanned so that a bad one is reported, rather
+			// than starting a new token.
+			digits := l.position
+			for isHexDigit(l.ch) || l.ch == '_' {
+				if l.ch != '_' && hexValue(l.ch) >= rune(base) {
+					l.error(MalformedNumber, l.Position(),
+						fmt.Sprintf("invalid digit %q in %s literal", l.ch, name))
+				}
+				l.advance()
+			}
+			if l.position == digits {
+				l.error(MalformedNumber, pos, fmt.Sprintf("%s literal has no digits", name))
+			}
+			return l.number(pos, start, true)
+		}
 	}
+
+	l.digits()
 	if l.ch == '.' && isDigit(l.peek()) {
 		l.advance()
-		for isDigit(l.ch) {
+		l.digits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		l.advance()
+		if l.ch == '+' || l.ch == '-' {
 			l.advance()
 		}
+		if !isDigit(l.ch) {
+			l.error(MalformedNumber, pos, "exponent has no digits")
+		}
+		l.digits()
+	}
+
+	return l.number(pos, start, false)
+}
+
+func numberBase(ch rune) (int, string) {
+	switch ch {
+	case 'x', 'X':
+		return 16, "hexadecimal"
+	case 'b', 'B':
+		return 2, "binary"
+	case 'o', 'O':
+		return 8, "octal"
+	}
+	return 0, ""
+}
+
+func (l *Lexer) digits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.advance()
+	}
+}
+
+// number returns the NUMBER token that starts at start, checking that any
+// underscores in it separate digits. In a prefixed literal, an underscore may
+// also follow the prefix (`0x_ff`).
+func (l *Lexer) number(pos token.Position, start int, prefixed bool) *token.Token {
+	literal := l.input[start:l.position]
+
+	digit := isDigit
+	if prefixed {
+		digit = isHexDigit
+	}
+
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '_' {
+			continue
+		}
+		afterDigit := i > 0 && digit(rune(literal[i-1])) || prefixed && i == 2
+		beforeDigit := i+1 < len(literal) && digit(rune(literal[i+1]))
+		if !afterDigit || !beforeDigit {
+			l.error(MalformedNumber, pos, "'_' must separate successive digits")
+			break
+		}
+	}
+
+	return token.New(token.NUMBER, literal)
+}
+
+// string scans a string literal, with its escape sequences decoded. A string
+// containing `${` is returned as a TEMPLATE_HEAD instead, and the lexer scans
+// the expression that follows as normal until the matching `}`.
+func (l *Lexer) string() *token.Token {
+	t := template{pos: l.Position(), quote: l.ch}
+	l.advance()
+
+	literal, interpolated := l.stringPart(t)
+	if interpolated {
+		l.templates = append(l.templates, t)
+		return token.New(token.TEMPLATE_HEAD, literal)
 	}
-	return token.New(token.NUMBER, l.input[start:l.position])
+	return token.New(token.STRING, literal)
 }
 
-func (l *Lexer) string(end byte) string {
+// rawString scans a backtick string. Nothing is escaped in it, and it may span
+// several lines.
+func (l *Lexer) rawString() *token.Token {
+	pos := l.Position()
 	l.advance()
 	start := l.position
 
-	for l.ch != end && !l.isAtEnd() {
-		if l.ch == '\n' {
-			l.line++
-		}
+	for l.ch != '`' && !l.isAtEnd() {
 		l.advance()
 	}
 
 	literal := l.input[start:l.position]
 
 	if l.isAtEnd() {
-		// TODO: Unterminated string error.
-		fmt.Println("string literal not terminated")
-		return literal
+		l.error(UnterminatedString, pos, "string literal not terminated")
+		return token.New(token.STRING, literal)
 	}
 
 	l.advance()
-	return literal
+	return token.New(token.STRING, literal)
+}
+
+// heredoc scans a raw string between triple quotes. Indentation common to all
+// of its lines is removed, as are the line breaks directly after the opening
+// quotes and directly before the closing ones, so the contents can be
+// indented to match the surrounding code.
+func (l *Lexer) heredoc() *token.Token {
+	pos := l.Position()
+	l.advance()
+	l.advance()
+	l.advance()
+	start := l.position
+
+	for !strings.HasPrefix(l.input[l.position:], `"""`) && !l.isAtEnd() {
+		l.advance()
+	}
+
+	literal := l.input[start:l.position]
+
+	if l.isAtEnd() {
+		l.error(UnterminatedString, pos, "string literal not terminated")
+		return token.New(token.STRING, trimIndent(literal))
+	}
+
+	l.advance()
+	l.advance()
+	l.advance()
+	return token.New(token.STRING, trimIndent(literal))
+}
+
+func trimIndent(s string) string {
+	lines := strings.Split(s, "\n")
+
+	if strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Blank lines don't count towards the common indentation.
+	var (
+		indent string
+		found  bool
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			indent, found = lineIndent, true
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// templateContinue scans the rest of an interpolated string after the `}`
+// that closes one of its expressions.
+func (l *Lexer) templateContinue() *token.Token {
+	t := l.templates[len(l.templates)-1]
+	l.advance()
+
+	literal, interpolated := l.stringPart(t)
+	if interpolated {
+		return token.New(token.TEMPLATE_MIDDLE, literal)
+	}
+	l.templates = l.templates[:len(l.templates)-1]
+	return token.New(token.TEMPLATE_TAIL, literal)
+}
+
+// stringPart scans a string up to its closing quote or the next `${`,
+// reporting which of the two it stopped at.
+func (l *Lexer) stringPart(t template) (string, bool) {
+	var out strings.Builder
+	for l.ch != t.quote && !l.isAtEnd() {
+		switch {
+		case l.ch == '\\':
+			l.escape(&out)
+		case l.ch == '$' && l.peek() == '{':
+			l.advance()
+			l.advance()
+			return out.String(), true
+		default:
+			// Copy the character as written, so that invalid UTF-8 is kept
+			// as it is rather than replaced.
+			out.WriteString(l.input[l.position:l.readPosition])
+			l.advance()
+		}
+	}
+
+	if l.isAtEnd() {
+		l.error(UnterminatedString, t.pos, "string literal not terminated")
+		return out.String(), false
+	}
+
+	l.advance()
+	return out.String(), false
+}
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'$':  '$',
+}
+
+// escape decodes the escape sequence at the current backslash into out. Like
+// JavaScript, `\xNN` and `\u{N...}` both give the code point with that hex
+// value. An invalid escape is reported and left in the string as written.
+func (l *Lexer) escape(out *strings.Builder) {
+	pos := l.Position()
+	start := l.position
+	l.advance()
+
+	if ch, ok := escapes[l.ch]; ok {
+		out.WriteRune(ch)
+		l.advance()
+		return
+	}
+
+	var (
+		value  rune
+		digits int
+		valid  bool
+	)
+	switch l.ch {
+	case 'x':
+		l.advance()
+		for digits < 2 && isHexDigit(l.ch) {
+			value = value<<4 | hexValue(l.ch)
+			digits++
+			l.advance()
+		}
+		valid = digits == 2
+	case 'u':
+		l.advance()
+		if l.ch == '{' {
+			l.advance()
+			for digits < 6 && isHexDigit(l.ch) {
+				value = value<<4 | hexValue(l.ch)
+				digits++
+				l.advance()
+			}
+			valid = digits > 0 && l.ch == '}' && utf8.ValidRune(value)
+			if l.ch == '}' {
+				l.advance()
+			}
+		}
+	default:
+		if !l.isAtEnd() {
+			l.advance()
+		}
+	}
+
+	if !valid {
+		literal := l.input[start:l.position]
+		l.error(InvalidEscape, pos, fmt.Sprintf("invalid escape sequence %s", literal))
+		out.WriteString(literal)
+		return
+	}
+	out.WriteRune(value)
 }
 
 func (l *Lexer) lineComment() string {
@@ -224,22 +638,19 @@ func (l *Lexer) lineComment() string {
 }
 
 func (l *Lexer) blockComment() string {
+	pos := l.Position()
 	l.advance()
 	l.advance()
 	start := l.position
 
 	for !(l.ch == '*' && l.peek() == '/') && !l.isAtEnd() {
-		if l.ch == '\n' {
-			l.line++
-		}
 		l.advance()
 	}
 
 	literal := strings.TrimSpace(l.input[start:l.position])
 
 	if l.isAtEnd() {
-		// TODO: Unterminated block comment error.
-		fmt.Println("comment not terminated")
+		l.error(UnterminatedComment, pos, "comment not terminated")
 		return literal
 	}
 
@@ -248,22 +659,80 @@ func (l *Lexer) blockComment() string {
 	return literal
 }
 
+// illegal reports the current character as illegal and returns an ILLEGAL
+// token for it.
+func (l *Lexer) illegal() *token.Token {
+	literal := l.input[l.position:l.readPosition]
+	if l.ch == utf8.RuneError && len(literal) == 1 {
+		l.error(IllegalCharacter, l.Position(), "illegal UTF-8 encoding")
+		return token.New(token.ILLEGAL, literal)
+	}
+	l.error(IllegalCharacter, l.Position(), fmt.Sprintf("illegal character %q", l.ch))
+	return newToken(token.ILLEGAL, l.ch)
+}
+
+func (l *Lexer) error(kind DiagnosticKind, pos token.Position, msg string) {
+	l.errors = append(l.errors, Diagnostic{Kind: kind, Pos: pos, Msg: msg})
+}
+
+// atLineEnd reports whether the lexer is at the end of a line. A comment that
+// runs to the end of the line counts as its end, as does reaching the end of
+// the input.
+func (l *Lexer) atLineEnd() bool {
+	switch {
+	case l.ch == '\n' || l.isAtEnd():
+		return true
+	case l.ch == '/' && l.peek() == '/':
+		return true
+	case l.ch == '/' && l.peek() == '*':
+		end := strings.Index(l.input[l.position:], "*/")
+		if end < 0 {
+			return true
+		}
+		return strings.Contains(l.input[l.position:l.position+end], "\n")
+	}
+	return false
+}
+
+func endsStatement(t token.TokenType) bool {
+	switch t {
+	case token.IDENT, token.NUMBER, token.STRING, token.TEMPLATE_TAIL, token.TRUE, token.FALSE, token.NIL,
+		token.RETURN, token.BREAK, token.CONTINUE,
+		token.RPAREN, token.RBRACKET, token.RBRACE, token.INC, token.DEC:
+		return true
+	}
+	return false
+}
+
 func (l *Lexer) isAtEnd() bool {
 	return l.position >= len(l.input)
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func isAlpha(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func hexValue(ch rune) rune {
+	switch {
+	case isDigit(ch):
+		return ch - '0'
+	case 'a' <= ch && ch <= 'f':
+		return ch - 'a' + 10
+	}
+	return ch - 'A' + 10
 }
 
-func isAlphaNumeric(ch byte) bool {
-	return isAlpha(ch) || isDigit(ch)
+// isLetter reports whether ch can start an identifier. Like Go, any Unicode
+// letter can, and identifiers may go on to contain any Unicode digit.
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-func newToken(tokenType token.TokenType, ch byte) *token.Token {
+func newToken(tokenType token.TokenType, ch rune) *token.Token {
 	return token.New(tokenType, string(ch))
 }
@@ -66,6 +66,133 @@ And more
 	testRunner(t, input, tests)
 }
 
+func TestHashLiteral(t *testing.T) {
+	input := `{a: 1, "b": 2}`
+	tests := []tokenTest{
+		{token.LBRACE, "{"},
+		{token.IDENT, "a"},
+		{token.COLON, ":"},
+		{token.NUMBER, "1"},
+		{token.COMMA, ","},
+		{token.STRING, "b"},
+		{token.COLON, ":"},
+		{token.NUMBER, "2"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestMemberAccess(t *testing.T) {
+	input := `user.greet(1.5)`
+	tests := []tokenTest{
+		{token.IDENT, "user"},
+		{token.DOT, "."},
+		{token.IDENT, "greet"},
+		{token.LPAREN, "("},
+		{token.NUMBER, "1.5"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `for i, v in arr { break; continue; }`
+	tests := []tokenTest{
+		{token.FOR, "for"},
+		{token.IDENT, "i"},
+		{token.COMMA, ","},
+		{token.IDENT, "v"},
+		{token.IN, "in"},
+		{token.IDENT, "arr"},
+		{token.LBRACE, "{"},
+		{token.BREAK, "break"},
+		{token.SEMICOLON, ";"},
+		{token.CONTINUE, "continue"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestConstKeyword(t *testing.T) {
+	input := "const x = 1\nconstant := 2"
+	tests := []tokenTest{
+		{token.CONST, "const"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.NUMBER, "1"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "constant"},
+		{token.DECLARE, ":="},
+		{token.NUMBER, "2"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	input := "a += 1; b -= 2; c *= 3; d /= 4; e %= 5\ni++\nj--\nk - -1"
+	tests := []tokenTest{
+		{token.IDENT, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.NUMBER, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "b"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.NUMBER, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "c"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.NUMBER, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "d"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.NUMBER, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "e"},
+		{token.MOD_ASSIGN, "%="},
+		{token.NUMBER, "5"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "i"},
+		{token.INC, "++"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "j"},
+		{token.DEC, "--"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "k"},
+		{token.MINUS, "-"},
+		{token.MINUS, "-"},
+		{token.NUMBER, "1"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestSpread(t *testing.T) {
+	input := `[...a, b.c]`
+	tests := []tokenTest{
+		{token.LBRACKET, "["},
+		{token.SPREAD, "..."},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "b"},
+		{token.DOT, "."},
+		{token.IDENT, "c"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
 func TestNextToken(t *testing.T) {
 	input := `test := nil;
 ten := 10.0;
@@ -170,15 +297,17 @@ if (5 <= 10) {
 		{token.FALSE, "false"},
 		{token.SEMICOLON, ";"},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
 
-		// 63
+		// 64
 		{token.COMMENT, "10 == 10; 10 != 9;"},
 
-		// 64
+		// 65
 		{token.COMMENT, "comment"},
 		{token.NUMBER, "10"},
+		{token.SEMICOLON, "\n"},
 
-		// 66
+		// 68
 		{token.NUMBER, "10"},
 		{token.EQ, "=="},
 		{token.STRING, "10"},
@@ -188,18 +317,343 @@ if (5 <= 10) {
 		{token.STRING, "9"},
 		{token.SEMICOLON, ";"},
 
-		// 74
+		// 76
 		{token.MOD, "%"},
 		{token.AND, "&&"},
 		{token.OR, "||"},
 
-		// 77
+		// 79
+		{token.LBRACKET, "["},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, "\n"},
+
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestSemicolonInsertion(t *testing.T) {
+	input := `x := 5
+y := x +
+  2
+return
+f(a,
+  b,
+) // done
+[1] /* inline */
+{} /* spans
+lines */ "s"
+if x {
+    break
+}
+continue`
+	tests := []tokenTest{
+		{token.IDENT, "x"},
+		{token.DECLARE, ":="},
+		{token.NUMBER, "5"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "y"},
+		{token.DECLARE, ":="},
+		{token.IDENT, "x"},
+		{token.PLUS, "+"},
+		{token.NUMBER, "2"},
+		{token.SEMICOLON, "\n"},
+		{token.RETURN, "return"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "b"},
+		{token.COMMA, ","},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, "\n"},
+		{token.COMMENT, "done"},
 		{token.LBRACKET, "["},
+		{token.NUMBER, "1"},
 		{token.RBRACKET, "]"},
+		{token.COMMENT, "inline"},
+		{token.SEMICOLON, "\n"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.COMMENT, "spans\nlines"},
+		{token.STRING, "s"},
+		{token.SEMICOLON, "\n"},
+		{token.IF, "if"},
+		{token.IDENT, "x"},
+		{token.LBRACE, "{"},
+		{token.BREAK, "break"},
+		{token.SEMICOLON, "\n"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.CONTINUE, "continue"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestTokenPositions(t *testing.T) {
+	at := func(offset, line, column int) token.Position {
+		return token.Position{Filename: "main.dr", Offset: offset, Line: line, Column: column}
+	}
+	input := "x := 5\n  \"hi\" + y"
+	tests := []struct {
+		expectedType token.TokenType
+		pos          token.Position
+		end          token.Position
+	}{
+		{token.IDENT, at(0, 1, 1), at(1, 1, 2)},
+		{token.DECLARE, at(2, 1, 3), at(4, 1, 5)},
+		{token.NUMBER, at(5, 1, 6), at(6, 1, 7)},
+		{token.SEMICOLON, at(6, 1, 7), at(6, 1, 7)},
+		{token.STRING, at(9, 2, 3), at(13, 2, 7)},
+		{token.PLUS, at(14, 2, 8), at(15, 2, 9)},
+		{token.IDENT, at(16, 2, 10), at(17, 2, 11)},
+		{token.SEMICOLON, at(17, 2, 11), at(17, 2, 11)},
+		{token.EOF, at(17, 2, 11), at(17, 2, 11)},
+	}
+
+	l := NewFile("main.dr", input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos != tt.pos {
+			t.Fatalf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.pos, tok.Pos)
+		}
+		if tok.End != tt.end {
+			t.Fatalf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.end, tok.End)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `café := "héllo"; 日本 = π2 + x٣`
+	tests := []tokenTest{
+		{token.IDENT, "café"},
+		{token.DECLARE, ":="},
+		{token.STRING, "héllo"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "日本"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "π2"},
+		{token.PLUS, "+"},
+		{token.IDENT, "x٣"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
+
+func TestUnicodePositions(t *testing.T) {
+	// Columns count bytes, like Go.
+	l := New("é := \"ü\" + ü")
+	expected := []int{1, 4, 7, 12, 14}
+	for i, column := range expected {
+		tok := l.NextToken()
+		if tok.Pos.Column != column {
+			t.Errorf("tests[%d] - column wrong for %q. expected=%d, got=%d",
+				i, tok.Literal, column, tok.Pos.Column)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"tab\there"`, "tab\there"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`'it\'s'`, "it's"},
+		{`"back\\slash"`, `back\slash`},
+		{`"\x41\x7a"`, "Az"},
+		{`"\xe9"`, "é"},
+		{`"\u{1F600}!"`, "\U0001F600!"},
+		{`"\r\n"`, "\r\n"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong for %s. expected=%q, got=%q", tt.input, token.STRING, tok.Type)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %s. expected=%q, got=%q", tt.input, tt.expected, tok.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Errorf("unexpected diagnostics for %s: %v", tt.input, l.Errors())
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"a ${b} c ${ {d: "${e}"}.d } f" + "\${g}"`
+	tests := []tokenTest{
+		{token.TEMPLATE_HEAD, "a "},
+		{token.IDENT, "b"},
+		{token.TEMPLATE_MIDDLE, " c "},
+		{token.LBRACE, "{"},
+		{token.IDENT, "d"},
+		{token.COLON, ":"},
+		{token.TEMPLATE_HEAD, ""},
+		{token.IDENT, "e"},
+		{token.TEMPLATE_TAIL, ""},
+		{token.RBRACE, "}"},
+		{token.DOT, "."},
+		{token.IDENT, "d"},
+		{token.TEMPLATE_TAIL, " f"},
+		{token.PLUS, "+"},
+		{token.STRING, "${g}"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+}
 
+func TestNumberLiterals(t *testing.T) {
+	input := `0xFF 0b1010 0o755 1e9 2.5e-3 1_000_000 1.5.len x.0`
+	tests := []tokenTest{
+		{token.NUMBER, "0xFF"},
+		{token.NUMBER, "0b1010"},
+		{token.NUMBER, "0o755"},
+		{token.NUMBER, "1e9"},
+		{token.NUMBER, "2.5e-3"},
+		{token.NUMBER, "1_000_000"},
+		{token.NUMBER, "1.5"},
+		{token.DOT, "."},
+		{token.IDENT, "len"},
+		{token.IDENT, "x"},
+		{token.DOT, "."},
+		{token.NUMBER, "0"},
+		{token.SEMICOLON, "\n"},
 		{token.EOF, ""},
 	}
 	testRunner(t, input, tests)
+
+	l := New(input)
+	l.Scan()
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected diagnostics: %v", l.Errors())
+	}
+}
+
+func TestRawStrings(t *testing.T) {
+	input := "x := `a\\n${b}\n\"c\"`\ny"
+	tests := []tokenTest{
+		{token.IDENT, "x"},
+		{token.DECLARE, ":="},
+		{token.STRING, "a\\n${b}\n\"c\""},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+	testRunner(t, input, tests)
+
+	l := New(input)
+	l.Scan()
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected diagnostics: %v", l.Errors())
+	}
+}
+
+func TestHeredocs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"""abc"""`, "abc"},
+		{"\"\"\"\n    SELECT *\n      FROM t\n\n    WHERE x = \"y\"\n    \"\"\"", "SELECT *\n  FROM t\n\nWHERE x = \"y\""},
+		{"\"\"\"\n\t{\n\t\t\"a\": \"\\n\"\n\t}\n\"\"\"", "{\n\t\"a\": \"\\n\"\n}"},
+		{"\"\"\"\"\"\"", ""},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong for %q. expected=%q, got=%q", tt.input, token.STRING, tok.Type)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %q. expected=%q, got=%q", tt.input, tt.expected, tok.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Errorf("unexpected diagnostics for %q: %v", tt.input, l.Errors())
+		}
+	}
+}
+
+func TestMultiLineStringPositions(t *testing.T) {
+	input := "a := `x\ny`\nb := \"\"\"\n  z\n  \"\"\"\nc"
+
+	l := New(input)
+	tokens := l.Scan()
+
+	last := tokens[len(tokens)-3]
+	if last.Type != token.IDENT || last.Literal != "c" {
+		t.Fatalf("wrong token. expected=IDENT c, got=%s %q", last.Type, last.Literal)
+	}
+	if last.Pos.String() != "6:1" {
+		t.Errorf("wrong position for c. expected=6:1, got=%s", last.Pos)
+	}
+	if end := tokens[2].End.String(); end != "2:3" {
+		t.Errorf("wrong end for raw string. expected=2:3, got=%s", end)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		kind     DiagnosticKind
+		expected string
+	}{
+		{`x := "abc`, UnterminatedString, `1:6: string literal not terminated`},
+		{"x := 1\n/* abc", UnterminatedComment, `2:1: comment not terminated`},
+		{`a & b`, IllegalCharacter, `1:3: illegal character '&'`},
+		{`a | b`, IllegalCharacter, `1:3: illegal character '|'`},
+		{`x := @`, IllegalCharacter, `1:6: illegal character '@'`},
+		{`0x`, MalformedNumber, `1:1: hexadecimal literal has no digits`},
+		{`0b2`, MalformedNumber, `1:3: invalid digit '2' in binary literal`},
+		{`2.5e+`, MalformedNumber, `1:1: exponent has no digits`},
+		{`_1 1_`, MalformedNumber, `1:4: '_' must separate successive digits`},
+		{`"a\qb"`, InvalidEscape, `1:3: invalid escape sequence \q`},
+		{`"\x4"`, InvalidEscape, `1:2: invalid escape sequence \x4`},
+		{`"\u41"`, InvalidEscape, `1:2: invalid escape sequence \u`},
+		{`"\u{110000}"`, InvalidEscape, `1:2: invalid escape sequence \u{110000}`},
+		{`"\u{41"`, InvalidEscape, `1:2: invalid escape sequence \u{41`},
+		{`x := "a ${b`, UnterminatedString, `1:6: string literal not terminated`},
+		{"x := `abc\n", UnterminatedString, `1:6: string literal not terminated`},
+		{`x := """abc""`, UnterminatedString, `1:6: string literal not terminated`},
+		{`x := "a ${b} c`, UnterminatedString, `1:6: string literal not terminated`},
+		{"x := \xff", IllegalCharacter, `1:6: illegal UTF-8 encoding`},
+		{`x := €`, IllegalCharacter, `1:6: illegal character '€'`},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		l.Scan()
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Errorf("wrong number of diagnostics for %q. expected=1, got=%d (%v)",
+				tt.input, len(errors), errors)
+			continue
+		}
+		if errors[0].Kind != tt.kind {
+			t.Errorf("wrong kind for %q. expected=%d, got=%d", tt.input, tt.kind, errors[0].Kind)
+		}
+		if errors[0].String() != tt.expected {
+			t.Errorf("wrong diagnostic for %q. expected=%q, got=%q",
+				tt.input, tt.expected, errors[0].String())
+		}
+	}
 }
 
 func TestScan(t *testing.T) {
@@ -0,0 +1,185 @@
+// Package lint finds code in a dara program that is valid, but likely to be a
+// mistake.
+package lint
+
+import (
+	"dara/ast"
+	"dara/token"
+	"fmt"
+)
+
+// Warning is a likely mistake found in a program.
+type Warning struct {
+	Pos token.Position
+	Msg string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Pos, w.Msg)
+}
+
+// Shadowing reports every `:=`, `const` and named function declaration that
+// shadows a name declared in an enclosing scope. It follows the scoping rules
+// of the evaluator: the program, each block and each function call have a
+// scope of their own, and a function's parameters share a scope with its body.
+// Parameters and loop variables are allowed to shadow without a warning, as
+// reusing a name for them is rarely a mistake.
+func Shadowing(program *ast.Program) []Warning {
+	c := &shadowChecker{}
+	c.open()
+	c.statements(program.Statements)
+	return c.warnings
+}
+
+type shadowChecker struct {
+	scopes   []map[string]token.Position // innermost last
+	warnings []Warning
+}
+
+func (c *shadowChecker) open() {
+	c.scopes = append(c.scopes, make(map[string]token.Position))
+}
+
+func (c *shadowChecker) close() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// declare adds name to the innermost scope, warning if it shadows a name from
+// an enclosing one. Declaring a name twice in one scope is left to the
+// evaluator to report.
+func (c *shadowChecker) declare(name *ast.Identifier) {
+	scope := c.scopes[len(c.scopes)-1]
+	if _, ok := scope[name.Value]; ok {
+		return
+	}
+
+	for i := len(c.scopes) - 2; i >= 0; i-- {
+		if pos, ok := c.scopes[i][name.Value]; ok {
+			c.warnings = append(c.warnings, Warning{
+				Pos: name.Pos(),
+				Msg: fmt.Sprintf("declaration of %s shadows declaration at line %d", name.Value, pos.Line),
+			})
+			break
+		}
+	}
+
+	scope[name.Value] = name.Pos()
+}
+
+// bind adds name to the innermost scope without checking for shadowing.
+func (c *shadowChecker) bind(name *ast.Identifier) {
+	c.scopes[len(c.scopes)-1][name.Value] = name.Pos()
+}
+
+func (c *shadowChecker) statements(statements []ast.Statement) {
+	// Named functions are declared when their block is entered.
+	for _, statement := range statements {
+		if fs, ok := statement.(*ast.FunctionStatement); ok {
+			c.declare(fs.Name)
+		}
+	}
+	for _, statement := range statements {
+		c.walk(statement)
+	}
+}
+
+func (c *shadowChecker) block(block *ast.BlockStatement) {
+	c.open()
+	c.statements(block.Statements)
+	c.close()
+}
+
+func (c *shadowChecker) function(fl *ast.FunctionLiteral) {
+	c.open()
+	for _, param := range fl.Parameters {
+		c.walk(param.Default)
+		c.bind(param.Name)
+	}
+	c.statements(fl.Body.Statements)
+	c.close()
+}
+
+func (c *shadowChecker) walk(node ast.Node) {
+	switch node := node.(type) {
+	case nil:
+		return
+
+	case *ast.ExpressionStatement:
+		c.walk(node.Expression)
+	case *ast.IncDecStatement:
+		c.walk(node.Target)
+	case *ast.ReturnStatement:
+		c.walk(node.ReturnValue)
+	case *ast.BlockStatement:
+		c.block(node)
+	case *ast.IfStatement:
+		c.walk(node.Condition)
+		c.block(node.Consequence)
+		c.walk(node.Alternative)
+	case *ast.ForStatement:
+		c.open()
+		c.walk(node.Init)
+		c.walk(node.Condition)
+		c.block(node.Body)
+		c.walk(node.Post)
+		c.close()
+	case *ast.ForInStatement:
+		c.walk(node.Iterable)
+		c.open()
+		if node.Key != nil {
+			c.bind(node.Key)
+		}
+		c.bind(node.Value)
+		c.block(node.Body)
+		c.close()
+	case *ast.FunctionStatement:
+		c.function(node.Function)
+	case *ast.ConstStatement:
+		c.walk(node.Value)
+		c.declare(node.Name)
+
+	case *ast.FunctionLiteral:
+		c.function(node)
+	case *ast.DeclareExpression:
+		c.walk(node.Value)
+		c.declare(node.Name)
+	case *ast.AssignExpression:
+		c.walk(node.Target)
+		c.walk(node.Value)
+	case *ast.PrefixExpression:
+		c.walk(node.Right)
+	case *ast.InfixExpression:
+		c.walk(node.Left)
+		c.walk(node.Right)
+	case *ast.InterpolatedString:
+		for _, part := range node.Parts {
+			c.walk(part)
+		}
+	case *ast.CallExpression:
+		c.walk(node.Function)
+		for _, arg := range node.Arguments {
+			c.walk(arg)
+		}
+	case *ast.IndexExpression:
+		c.walk(node.Left)
+		c.walk(node.Index)
+	case *ast.SliceExpression:
+		c.walk(node.Left)
+		c.walk(node.Start)
+		c.walk(node.Stop)
+		c.walk(node.Step)
+	case *ast.MemberExpression:
+		c.walk(node.Object)
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			c.walk(el)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			c.walk(pair.Key)
+			c.walk(pair.Value)
+		}
+	case *ast.SpreadElement:
+		c.walk(node.Value)
+	}
+}
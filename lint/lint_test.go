@@ -0,0 +1,54 @@
+package lint
+
+import (
+	"dara/lexer"
+	"dara/parser"
+	"testing"
+)
+
+func TestShadowing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"x := 1; y := 2", nil},
+		{"x := 1\nif true {\n  x := 2\n}", []string{"3:3: declaration of x shadows declaration at line 1"}},
+		{"x := 1\nif true { } else { x := 2 }", []string{"2:20: declaration of x shadows declaration at line 1"}},
+		{"x := 1\nf := fn() { x := 2 }", []string{"2:13: declaration of x shadows declaration at line 1"}},
+		{"x := 1\nfn f() {\n  fn x() {}\n}", []string{"3:6: declaration of x shadows declaration at line 1"}},
+		{"fn f() {\n  if true { f := 1 }\n}", []string{"2:13: declaration of f shadows declaration at line 1"}},
+		{"i := 0\nfor i := 0; i < 3; i++ { }", []string{"2:5: declaration of i shadows declaration at line 1"}},
+		{"for i := 0; i < 3; i++ {\n  i := 1\n}", []string{"2:3: declaration of i shadows declaration at line 1"}},
+		{"for v in [1] {\n  v := 2\n}", []string{"2:3: declaration of v shadows declaration at line 1"}},
+		{"x := 1\ng := fn(x, y = x) { x }", nil},
+		{"v := 1\nfor v in [1] { }", nil},
+		{"if true { x := 1 }\nif true { x := 2 }", nil},
+		{"f := fn() { x := 1 }\nx := 2", nil},
+		{"x := 1\nx := 2", nil},
+		{"const x = 1\nif true { const x = 2 }", []string{"2:17: declaration of x shadows declaration at line 1"}},
+		{"x := 1\n[fn() { x := 2 }, {a: fn() { x := 3 }}]", []string{
+			"2:9: declaration of x shadows declaration at line 1",
+			"2:30: declaration of x shadows declaration at line 1",
+		}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		warnings := Shadowing(program)
+		if len(warnings) != len(tt.expected) {
+			t.Errorf("wrong number of warnings for %q. expected=%d, got=%d (%v)",
+				tt.input, len(tt.expected), len(warnings), warnings)
+			continue
+		}
+		for i, warning := range warnings {
+			if warning.String() != tt.expected[i] {
+				t.Errorf("wrong warning for %q. expected=%q, got=%q", tt.input, tt.expected[i], warning.String())
+			}
+		}
+	}
+}
@@ -2,12 +2,25 @@ package main
 
 import (
 	"dara/repl"
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
 )
 
 func main() {
+	shadow := flag.Bool("shadow", false,
+		"warn about declarations that shadow a name from an enclosing scope")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		opts := repl.Options{WarnShadowing: *shadow}
+		if !repl.RunFile(flag.Arg(0), os.Stderr, opts) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
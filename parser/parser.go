@@ -5,7 +5,9 @@ import (
 	"dara/lexer"
 	"dara/token"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -20,26 +22,33 @@ const (
 	PREFIX          // -X or !X
 	CALL            // myFunction(X)
 	INDEX           // array[index]
+	MEMBER          // object.property
 )
 
 var precedences = map[token.TokenType]int{
-	token.DECLARE:  ASSIGN,
-	token.ASSIGN:   ASSIGN,
-	token.OR:       OR,
-	token.AND:      AND,
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.LT_EQ:    LESSGREATER,
-	token.GT_EQ:    LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.MOD:      PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.DECLARE:         ASSIGN,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.MOD_ASSIGN:      ASSIGN,
+	token.OR:              OR,
+	token.AND:             AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.MOD:             PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.DOT:             MEMBER,
 }
 
 type (
@@ -50,9 +59,24 @@ type (
 type Parser struct {
 	l         *lexer.Lexer
 	errors    []string
+	lexErrors int // number of lexer diagnostics already merged into errors
 	curToken  token.Token
 	peekToken token.Token
 
+	// depth is the number of braces curToken is nested in. A closing brace
+	// counts as inside the braces it closes.
+	depth int
+
+	// loops is the number of loops curToken is in, counting from the function
+	// literal it is in, if any. break and continue are only allowed in loops.
+	loops int
+
+	// panicking is set once an error has been reported in the current
+	// statement. Further errors are dropped until the parser has skipped to
+	// the start of the next statement, as they are usually caused by the
+	// first.
+	panicking bool
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -76,9 +100,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.NIL, p.parseNil)
 	p.registerPrefix(token.STRING, p.parseString)
+	p.registerPrefix(token.TEMPLATE_HEAD, p.parseInterpolatedString)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.AND, p.parseInfixExpression)
@@ -97,7 +123,13 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.DECLARE, p.parseDeclareExpression)
 	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MOD_ASSIGN, p.parseAssignExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	return p
 }
@@ -119,16 +151,81 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// parseStatement parses the statement starting at curToken. If it fails to
+// parse, the tokens up to the end of the statement are skipped and a
+// BadStatement is returned in its place, so that parsing can carry on and
+// report errors in later statements. An expression statement is kept, with
+// the broken part of it replaced by a BadExpression.
 func (p *Parser) parseStatement() ast.Statement {
+	tok, depth, panicking := p.curToken, p.depth, p.panicking
+
+	// A statement nested in one that has already failed, like one in the
+	// body of a broken if statement, leaves recovery to the outer statement.
+	stmt := p.parseStatementKind()
+	if !p.panicking || panicking {
+		return stmt
+	}
+
+	p.synchronize(depth)
+	p.panicking = false
+
+	if stmt, ok := stmt.(*ast.ExpressionStatement); ok {
+		return stmt
+	}
+	return &ast.BadStatement{Token: tok, To: p.curToken.End}
+}
+
+// synchronize skips to the last token of the statement that failed to parse:
+// a semicolon, the token before a closing brace or a keyword that starts a
+// statement, or the closing brace of the enclosing block if the statement ran
+// into it. depth is the nesting depth of the statement.
+func (p *Parser) synchronize(depth int) {
+	for !p.curTokenIs(token.EOF) && !p.peekTokenIs(token.EOF) {
+		if p.depth == depth && (p.curTokenIs(token.SEMICOLON) || p.curTokenIs(token.RBRACE)) {
+			return
+		}
+		if p.peekDepth() == depth && (p.peekTokenIs(token.RBRACE) || startsStatement(p.peekToken.Type)) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+func startsStatement(t token.TokenType) bool {
+	switch t {
+	case token.IF, token.FOR, token.RETURN, token.BREAK, token.CONTINUE, token.FUNCTION, token.CONST:
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseStatementKind() ast.Statement {
 	switch p.curToken.Type {
+	case token.SEMICOLON:
+		return nil
 	case token.COMMENT:
 		return p.parseComment()
 	case token.RETURN:
 		return p.parseReturnStatement()
 	case token.IF:
-		return p.parseIfStatement()
+		if stmt := p.parseIfStatement(); stmt != nil {
+			return stmt
+		}
+		return nil
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+	case token.CONST:
+		return p.parseConstStatement()
 	}
-	return p.parseExpressionStatement()
+	return p.parseSimpleStatement()
 }
 
 func (p *Parser) parseComment() ast.Statement {
@@ -138,11 +235,34 @@ func (p *Parser) parseComment() ast.Statement {
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
-	p.nextToken()
+	if !p.peekTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) && !p.panicking {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.expectNextToken(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	if !p.expectNextToken(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) && !p.panicking {
 		p.nextToken()
 	}
 
@@ -167,7 +287,11 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 		switch {
 		case p.peekTokenIs(token.IF):
 			p.nextToken()
-			expression.Alternative = p.parseIfStatement()
+			alternative := p.parseIfStatement()
+			if alternative == nil {
+				return nil
+			}
+			expression.Alternative = alternative
 		case p.peekTokenIs(token.LBRACE):
 			p.nextToken()
 			expression.Alternative = p.parseBlockStatement()
@@ -180,10 +304,118 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	return expression
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
-	stmt := &ast.ExpressionStatement{Token: p.curToken}
+// parseForStatement parses all three loop forms: `for cond { }`, the C-style
+// `for init; cond; post { }` and the range `for key, value in iterable { }`.
+// Omitting the condition (`for { }`) loops until a break or return.
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Body = p.parseLoopBody()
+		return stmt
+	}
+
+	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.IN)) {
+		return p.parseForInStatement(stmt.Token)
+	}
+
+	var exp ast.Expression
+	if !p.curTokenIs(token.SEMICOLON) {
+		exp = p.parseExpression(LOWEST)
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+	}
+
+	if p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = exp
+
+		if !p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+			stmt.Condition = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectNextToken(token.SEMICOLON) {
+			return nil
+		}
+
+		if !p.peekTokenIs(token.LBRACE) {
+			p.nextToken()
+			stmt.Post = p.parseSimpleStatement()
+		}
+	} else {
+		stmt.Condition = exp
+	}
+
+	if !p.expectNextToken(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	return stmt
+}
+
+func (p *Parser) parseForInStatement(forToken token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: forToken}
 
-	stmt.Expression = p.parseExpression(LOWEST)
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectNextToken(token.IDENT) {
+			return nil
+		}
+
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectNextToken(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectNextToken(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	return stmt
+}
+
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loops++
+	body := p.parseBlockStatement()
+	p.loops--
+	return body
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	if p.loops == 0 {
+		p.appendError("break outside of a loop")
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+	if p.loops == 0 {
+		p.appendError("continue outside of a loop")
+	}
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -192,17 +424,59 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+// parseSimpleStatement parses an expression statement, or an increment or
+// decrement statement (`x++`).
+func (p *Parser) parseSimpleStatement() ast.Statement {
+	exp := &ast.ExpressionStatement{Token: p.curToken}
+	exp.Expression = p.parseExpression(LOWEST)
+
+	var stmt ast.Statement = exp
+	if p.peekTokenIs(token.INC) || p.peekTokenIs(token.DEC) {
+		p.nextToken()
+		if !isAssignable(exp.Expression) {
+			p.appendErrorAt(exp.Pos(), fmt.Sprintf("can not assign to %s", exp.Expression))
+			return exp
+		}
+		stmt = &ast.IncDecStatement{Token: p.curToken, Target: exp.Expression}
+	}
+
+	// After an error the semicolon is left for synchronize, as the statement
+	// may have run into the closing brace of its block.
+	if p.peekTokenIs(token.SEMICOLON) && !p.panicking {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseNumberLiteral parses a NUMBER token. Literals with a fraction or an
+// exponent are floating point NumberLiterals, anything else is an
+// IntegerLiteral.
 func (p *Parser) parseNumberLiteral() ast.Expression {
+	literal := p.curToken.Literal
+
+	if isInteger(literal) {
+		lit := &ast.IntegerLiteral{Token: p.curToken}
+
+		value, ok := parseInteger(literal)
+		if !ok {
+			p.numberError("integer")
+			return nil
+		}
+
+		lit.Value = value
+		return lit
+	}
+
 	lit := &ast.NumberLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	value, err := strconv.ParseFloat(literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as float64", p.curToken.Literal)
-		p.appendError(msg)
+		p.numberError("float64")
 		return nil
 	}
 
@@ -210,6 +484,31 @@ func (p *Parser) parseNumberLiteral() ast.Expression {
 	return lit
 }
 
+func hasBasePrefix(literal string) bool {
+	return len(literal) > 1 && literal[0] == '0' && strings.ContainsAny(literal[1:2], "xXbBoO")
+}
+
+func isInteger(literal string) bool {
+	return hasBasePrefix(literal) || !strings.ContainsAny(literal, ".eE")
+}
+
+func parseInteger(literal string) (*big.Int, bool) {
+	if hasBasePrefix(literal) {
+		return new(big.Int).SetString(literal, 0)
+	}
+	// Base 0 would read a leading zero as an octal prefix.
+	return new(big.Int).SetString(strings.ReplaceAll(literal, "_", ""), 10)
+}
+
+func (p *Parser) numberError(kind string) {
+	// A malformed literal has already been reported by the lexer.
+	if p.lexerReported(p.curToken) {
+		return
+	}
+	msg := fmt.Sprintf("could not parse %q as %s", p.curToken.Literal, kind)
+	p.appendError(msg)
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -235,6 +534,34 @@ func (p *Parser) parseString() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	exp := &ast.InterpolatedString{Token: p.curToken}
+	exp.Parts = append(exp.Parts, p.parseString())
+
+	for {
+		if p.peekTokenIs(token.TEMPLATE_MIDDLE) || p.peekTokenIs(token.TEMPLATE_TAIL) {
+			p.appendErrorAt(p.peekToken.Pos, "expected expression in string interpolation")
+			return nil
+		}
+
+		p.nextToken()
+		exp.Parts = append(exp.Parts, p.parseExpression(LOWEST))
+
+		switch {
+		case p.peekTokenIs(token.TEMPLATE_MIDDLE):
+			p.nextToken()
+			exp.Parts = append(exp.Parts, p.parseString())
+		case p.peekTokenIs(token.TEMPLATE_TAIL):
+			p.nextToken()
+			exp.Parts = append(exp.Parts, p.parseString())
+			return exp
+		default:
+			p.peekError(token.TEMPLATE_MIDDLE, token.TEMPLATE_TAIL)
+			return nil
+		}
+	}
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
 
@@ -249,42 +576,129 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
+	if !p.parseFunction(lit) {
+		return nil
+	}
+	return lit
+}
+
+// parseFunctionStatement parses a named function declaration,
+// `fn name(a, b) { }`.
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
 
-	if !p.expectNextToken(token.LPAREN) {
+	p.nextToken()
+	stmt := &ast.FunctionStatement{
+		Name:     &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+		Function: lit,
+	}
+
+	if !p.parseFunction(lit) {
 		return nil
 	}
+	return stmt
+}
+
+// parseFunction parses the parameters and body of a function, from the token
+// before the opening parenthesis.
+func (p *Parser) parseFunction(lit *ast.FunctionLiteral) bool {
+	if !p.expectNextToken(token.LPAREN) {
+		return false
+	}
 
 	lit.Parameters = p.parseFunctionParameters()
 
 	if !p.expectNextToken(token.LBRACE) {
-		return nil
+		return false
 	}
 
+	// A function's body is not part of any loop it is written in.
+	loops := p.loops
+	p.loops = 0
 	lit.Body = p.parseBlockStatement()
+	p.loops = loops
 
-	return lit
+	return true
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Rbracket = p.curToken.Pos
 	return array
 }
 
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		if p.curTokenIs(token.SPREAD) {
+			hash.Pairs = append(hash.Pairs, ast.HashPair{Key: p.parseSpreadElement()})
+
+			if !p.peekTokenIs(token.RBRACE) && !p.expectNextToken(token.COMMA) {
+				return nil
+			}
+			continue
+		}
+
+		key := p.parseHashKey()
+
+		if !p.expectNextToken(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectNextToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectNextToken(token.RBRACE) {
+		return nil
+	}
+
+	hash.Rbrace = p.curToken.Pos
+
+	return hash
+}
+
+// parseHashKey parses the key of a hash pair. A bare identifier is shorthand
+// for a string key (`{a: 1}` is the same as `{"a": 1}`), anything else is
+// parsed as a regular expression.
+func (p *Parser) parseHashKey() ast.Expression {
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+		return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	}
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
 	p.nextToken()
+	depth := p.depth
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
+		// A statement that failed to parse can run into the closing brace.
+		if p.curTokenIs(token.RBRACE) && p.depth == depth {
+			break
+		}
 		p.nextToken()
 	}
 
+	block.Rbrace = p.curToken.Pos
+
 	return block
 }
 
@@ -305,6 +719,7 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Rparen = p.curToken.Pos
 	return exp
 }
 
@@ -322,13 +737,18 @@ func (p *Parser) parseDeclareExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// parseAssignExpression parses an assignment with `=` or one of the compound
+// assignment operators like `+=`.
 func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
-	name, ok := left.(*ast.Identifier)
-	if !ok {
-		p.appendError("expected identifier on left of =")
+	if !isAssignable(left) {
+		p.appendErrorAt(left.Pos(), fmt.Sprintf("can not assign to %s", left))
 		return nil
 	}
-	exp := &ast.AssignExpression{Token: p.curToken, Name: name}
+	exp := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   left,
+		Operator: strings.TrimSuffix(p.curToken.Literal, "="),
+	}
 
 	p.nextToken()
 	exp.Value = p.parseExpression(LOWEST)
@@ -336,28 +756,89 @@ func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// isAssignable reports whether exp can be assigned to: a variable, an element
+// of an array or object (`a[0]`), or an object member (`a.b`).
+func isAssignable(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 
-	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	if !p.expectNextToken(token.RBRACKET) {
+		return nil
+	}
+
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index, Rbracket: p.curToken.Pos}
+}
+
+// parseSliceExpression parses the rest of `left[start:end:step]` once the
+// first colon has been reached. Every part of the slice is optional.
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.Stop = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 
 	if !p.expectNextToken(token.RBRACKET) {
 		return nil
 	}
 
+	exp.Rbracket = p.curToken.Pos
+
 	return exp
 }
 
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: left}
+
+	if !p.expectNextToken(token.IDENT) {
+		return nil
+	}
+
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+// parseExpression parses an expression, returning a BadExpression for any
+// part of it that fails to parse.
 func (p *Parser) parseExpression(precedence int) ast.Expression {
+	tok := p.curToken
+
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		return &ast.BadExpression{Token: tok, To: p.curToken.End}
 	}
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	for leftExp != nil && !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -368,6 +849,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		leftExp = infix(leftExp)
 	}
 
+	if leftExp == nil {
+		return &ast.BadExpression{Token: tok, To: p.curToken.End}
+	}
 	return leftExp
 }
 
@@ -380,12 +864,15 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	list = append(list, p.parseListElement())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if p.peekTokenIs(end) {
+			break
+		}
 		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+		list = append(list, p.parseListElement())
 	}
 
 	if !p.expectNextToken(end) {
@@ -395,31 +882,96 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	var identifiers []*ast.Identifier
-
-	if p.peekTokenIs(token.RPAREN) {
-		p.nextToken()
-		return identifiers
+// parseListElement parses a single element of an array literal or call
+// arguments, either of which may spread an array into the list.
+func (p *Parser) parseListElement() ast.Expression {
+	if p.curTokenIs(token.SPREAD) {
+		return p.parseSpreadElement()
 	}
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseSpreadElement() ast.Expression {
+	spread := &ast.SpreadElement{Token: p.curToken}
 
 	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	return spread
+}
 
-	for p.peekTokenIs(token.COMMA) {
+// parseFunctionParameters parses a parameter list. A parameter may have a
+// default value (`fn(a, b = 10)`), in which case every parameter after it
+// needs one too, and the last may be a rest parameter (`fn(a, ...rest)`).
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	var params []*ast.Parameter
+
+	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
+		return params
+	}
+
+	for {
+		param := p.parseParameter()
+		if param == nil {
+			return nil
+		}
+		for _, other := range params {
+			if other.Name.Value == param.Name.Value {
+				p.appendErrorAt(param.Pos(), fmt.Sprintf("duplicate parameter %s", param.Name))
+				return nil
+			}
+		}
+		if len(params) > 0 && params[len(params)-1].Rest {
+			p.appendErrorAt(params[len(params)-1].Pos(), "rest parameter must be last")
+			return nil
+		}
+		if param.Default == nil && !param.Rest && len(params) > 0 && params[len(params)-1].Default != nil {
+			p.appendErrorAt(param.Pos(), fmt.Sprintf(
+				"parameter %s without a default value follows one with a default", param.Name))
+			return nil
+		}
+		params = append(params, param)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
 	}
 
 	if !p.expectNextToken(token.RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	return params
+}
+
+func (p *Parser) parseParameter() *ast.Parameter {
+	rest := p.peekTokenIs(token.SPREAD)
+	if rest {
+		p.nextToken()
+	}
+
+	if !p.expectNextToken(token.IDENT) {
+		return nil
+	}
+
+	param := &ast.Parameter{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}, Rest: rest}
+
+	if rest && p.peekTokenIs(token.ASSIGN) {
+		p.appendErrorAt(p.peekToken.Pos, fmt.Sprintf("rest parameter %s can not have a default value", param.Name))
+		return nil
+	}
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+	}
+
+	return param
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -448,8 +1000,38 @@ func (p *Parser) expectNextToken(t token.TokenType) bool {
 }
 
 func (p *Parser) nextToken() {
+	p.depth = p.peekDepth()
 	p.curToken = p.peekToken
 	p.peekToken = *p.l.NextToken()
+
+	// Lexer diagnostics are merged in as soon as the token they belong to is
+	// read, keeping them in order with the parser's own errors. They are never
+	// dropped while panicking, as they don't cascade.
+	for _, d := range p.l.Errors()[p.lexErrors:] {
+		p.errors = append(p.errors, d.String())
+	}
+	p.lexErrors = len(p.l.Errors())
+}
+
+// lexerReported reports whether the lexer found a problem within tok.
+func (p *Parser) lexerReported(tok token.Token) bool {
+	for _, d := range p.l.Errors()[:p.lexErrors] {
+		if d.Pos.Offset >= tok.Pos.Offset && d.Pos.Offset < tok.End.Offset {
+			return true
+		}
+	}
+	return false
+}
+
+// peekDepth returns the nesting depth of peekToken.
+func (p *Parser) peekDepth() int {
+	switch {
+	case p.curTokenIs(token.LBRACE):
+		return p.depth + 1
+	case p.curTokenIs(token.RBRACE) && p.depth > 0:
+		return p.depth - 1
+	}
+	return p.depth
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -480,17 +1062,39 @@ func (p *Parser) peekError(t ...token.TokenType) {
 		}
 		return joined
 	}()
-	msg := fmt.Sprintf("expected next token to be %s, received %s", expected, p.peekToken.Type)
-	p.appendError(msg)
+	msg := fmt.Sprintf("expected next token to be %s, received %s", expected, describe(p.peekToken))
+	p.appendErrorAt(p.peekToken.Pos, msg)
+}
+
+// describe names tok for an error message. A semicolon inserted at the end of
+// a line is called a newline, as there is no semicolon in the source.
+func describe(tok token.Token) string {
+	if tok.Type == token.SEMICOLON && tok.Literal == "\n" {
+		return "newline"
+	}
+	return string(tok.Type)
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	if t == token.ILLEGAL {
+		// The lexer has already reported the illegal character.
+		return
+	}
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
 	p.appendError(msg)
 }
 
+// appendError records an error at the current token.
 func (p *Parser) appendError(s string) {
-	line := p.l.Position()
-	msg := fmt.Sprintf("line %d: %s", line, s)
+	p.appendErrorAt(p.curToken.Pos, s)
+}
+
+func (p *Parser) appendErrorAt(pos token.Position, s string) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+
+	msg := fmt.Sprintf("%s: %s", pos, s)
 	p.errors = append(p.errors, msg)
 }
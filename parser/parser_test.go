@@ -43,6 +43,51 @@ func TestReturnStatements(t *testing.T) {
 	}
 }
 
+func TestStatementsWithoutSemicolons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"x := 5\ny := 6", []string{"x := 5;", "y := 6;"}},
+		{"x = 5\nx = 6", []string{"x = 5", "x = 6"}},
+		{"a +\nb\nc", []string{"(a + b)", "c"}},
+		{"return x\nreturn\nreturn y", []string{"return x;", "return ;", "return y;"}},
+		{"fn() {\n  return\n}", []string{"fn() { return ; }"}},
+		{"if x {\n  y\n} else if z {\n  w\n} else {\n  v\n}\nu", []string{
+			"if x { y } else {if z { w } else {v } }", "u",
+		}},
+		{"for x {\n  break\n}\nfor {\n  continue\n}", []string{"for x { break; }", "for { continue; }"}},
+		{"for i := 0; i < 3; i = i + 1 {\n  i\n}", []string{"for i := 0; (i < 3); i = (i + 1) { i }"}},
+		{"for k, v in {a: 1} {\n  v\n}", []string{`for k, v in {"a": 1} { v }`}},
+		{"x := {\n  a: 1,\n  b: [\n    2,\n  ],\n}", []string{`x := {"a": 1, "b": [2]};`}},
+		{"f(\n  a,\n  b,\n)\n[\n  1,\n  2,\n]", []string{"f(a, b)", "[1, 2]"}},
+		{"x := 1 // one\n// two\ny := 2", []string{"x := 1;", "one", "two", "y := 2;"}},
+		{"x\n\n\n;;\ny", []string{"x", "y"}},
+	}
+
+	for _, tt := range tests {
+		var (
+			l       = lexer.New(tt.input)
+			p       = New(l)
+			program = p.ParseProgram()
+		)
+
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != len(tt.expected) {
+			t.Fatalf("wrong number of statements for %q. want=%d, got=%d (%q)",
+				tt.input, len(tt.expected), len(program.Statements), program.String())
+		}
+
+		for i, stmt := range program.Statements {
+			if stmt.String() != tt.expected[i] {
+				t.Errorf("program.Statements[%d] wrong for %q. expected=%q, got=%q",
+					i, tt.input, tt.expected[i], stmt.String())
+			}
+		}
+	}
+}
+
 func TestDeclareExpression(t *testing.T) {
 	input := "test := 5;"
 
@@ -77,6 +122,43 @@ func TestDeclareExpression(t *testing.T) {
 	}
 }
 
+func TestConstStatement(t *testing.T) {
+	input := "const limit = 5 * 2\nconst name = \"dara\";"
+
+	var (
+		l       = lexer.New(input)
+		p       = New(l)
+		program = p.ParseProgram()
+	)
+
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"limit", "const limit = (5 * 2);"},
+		{"name", `const name = "dara";`},
+	}
+
+	for i, tt := range tests {
+		stmt, ok := program.Statements[i].(*ast.ConstStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.ConstStatement. got=%T", i, program.Statements[i])
+		}
+		if stmt.Name.Value != tt.name {
+			t.Errorf("stmt.Name.Value not %s. got=%s", tt.name, stmt.Name.Value)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
 func TestAssignStatement(t *testing.T) {
 	input := "test = 5;"
 
@@ -102,8 +184,8 @@ func TestAssignStatement(t *testing.T) {
 		t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
 	}
 
-	if ident.Name.Value != "test" {
-		t.Errorf("identifier not %s. got=%s", "test", ident.Name.Value)
+	if !testIdentifier(t, ident.Target, "test") {
+		return
 	}
 
 	if ident.Value.TokenLiteral() != "5" {
@@ -111,6 +193,101 @@ func TestAssignStatement(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		expected string
+	}{
+		{"x += 1", "+", "x += 1"},
+		{"x -= y * 2", "-", "x -= (y * 2)"},
+		{"x *= 3", "*", "x *= 3"},
+		{"x /= 4", "/", "x /= 4"},
+		{"x %= 5", "%", "x %= 5"},
+		{"x = 6", "", "x = 6"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+		}
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator wrong for %q. expected=%q, got=%q", tt.input, tt.operator, exp.Operator)
+		}
+		if exp.String() != tt.expected {
+			t.Errorf("exp.String() wrong. expected=%q, got=%q", tt.expected, exp.String())
+		}
+	}
+}
+
+func TestAssignmentTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   string
+		expected string
+	}{
+		{"a[0] = 5", "(a[0])", "(a[0]) = 5"},
+		{"a.b = 5", "(a.b)", "(a.b) = 5"},
+		{"a.b[c] += 1", "((a.b)[c])", "((a.b)[c]) += 1"},
+		{"a[0]++", "(a[0])", "(a[0])++"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		var target ast.Expression
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ExpressionStatement:
+			exp, ok := stmt.Expression.(*ast.AssignExpression)
+			if !ok {
+				t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+			}
+			target = exp.Target
+		case *ast.IncDecStatement:
+			target = stmt.Target
+		default:
+			t.Fatalf("unexpected statement %T", stmt)
+		}
+
+		if target.String() != tt.target {
+			t.Errorf("target wrong for %q. expected=%q, got=%q", tt.input, tt.target, target.String())
+		}
+		if s := program.Statements[0].String(); s != tt.expected {
+			t.Errorf("String() wrong for %q. expected=%q, got=%q", tt.input, tt.expected, s)
+		}
+	}
+}
+
+func TestIncDecStatement(t *testing.T) {
+	input := "x++\ny--; z"
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	}
+
+	for i, expected := range []string{"x++", "y--"} {
+		stmt, ok := program.Statements[i].(*ast.IncDecStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.IncDecStatement. got=%T", i, program.Statements[i])
+		}
+		if stmt.String() != expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", expected, stmt.String())
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	input := "true;"
 
@@ -167,6 +344,83 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestStringLiteralRoundTrip(t *testing.T) {
+	inputs := []string{
+		`"a\nb"`,
+		`"say \"hi\""`,
+		`'it\'s'`,
+		`"\u{1F600} \x41 \\"`,
+	}
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		p = New(lexer.New(program.String()))
+		reparsed := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		first := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral)
+		second := reparsed.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral)
+		if first.Value != second.Value {
+			t.Errorf("value changed for %s. first=%q, second=%q", input, first.Value, second.Value)
+		}
+	}
+}
+
+func TestParsingInterpolatedString(t *testing.T) {
+	input := `"hello ${user.name}, you have ${len(items) + 1} items"`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	expected := []string{`"hello "`, "(user.name)", `", you have "`, "(len(items) + 1)", `" items"`}
+	if len(str.Parts) != len(expected) {
+		t.Fatalf("wrong number of parts. expected=%d, got=%d", len(expected), len(str.Parts))
+	}
+	for i, part := range str.Parts {
+		if part.String() != expected[i] {
+			t.Errorf("parts[%d] wrong. expected=%s, got=%s", i, expected[i], part.String())
+		}
+	}
+
+	if str.String() != `"hello ${(user.name)}, you have ${(len(items) + 1)} items"` {
+		t.Errorf("str.String() wrong. got=%s", str.String())
+	}
+}
+
+func TestParsingInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "1:6: expected expression in string interpolation"},
+		{`"a ${b c}"`, "1:8: expected next token to be TEMPLATE_MIDDLE or TEMPLATE_TAIL, received IDENT"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 func TestParsingArrayLiteral(t *testing.T) {
 	input := `[1, 2 * 3, 4 + 5]`
 
@@ -188,7 +442,7 @@ func TestParsingArrayLiteral(t *testing.T) {
 		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
 	}
 
-	testNumberLiteral(t, array.Elements[0], 1)
+	testIntegerLiteral(t, array.Elements[0], 1)
 	testInfixExpression(t, array.Elements[1], 2, "*", 3)
 	testInfixExpression(t, array.Elements[2], 4, "+", 5)
 }
@@ -219,6 +473,352 @@ func TestParsingIndexExpression(t *testing.T) {
 	}
 }
 
+func TestParsingSpreadElements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[...a, ...b]", "[...a, ...b]"},
+		{"[1, ...a.b, 2]", "[1, ...(a.b), 2]"},
+		{"f(...args)", "f(...args)"},
+		{"f(a, ...[b, c])", "f(a, ...[b, c])"},
+		{"{...defaults, ...overrides}", "{...defaults, ...overrides}"},
+		{`{"a": 1, ...b, "c": 2}`, `{"a": 1, ...b, "c": 2}`},
+	}
+
+	for _, tt := range tests {
+		var (
+			l       = lexer.New(tt.input)
+			p       = New(l)
+			program = p.ParseProgram()
+		)
+
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.New("[1, ...a]"))
+	program := p.ParseProgram()
+	array := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+	spread, ok := array.Elements[1].(*ast.SpreadElement)
+	if !ok {
+		t.Fatalf("array.Elements[1] not *ast.SpreadElement. got=%T", array.Elements[1])
+	}
+	testIdentifier(t, spread.Value, "a")
+}
+
+func TestParsingSliceExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		start interface{}
+		stop  interface{}
+		step  interface{}
+	}{
+		{"array[1:3]", 1, 3, nil},
+		{"array[:3]", nil, 3, nil},
+		{"array[1:]", 1, nil, nil},
+		{"array[:]", nil, nil, nil},
+		{"array[::2]", nil, nil, 2},
+		{"array[a:b:c]", "a", "b", "c"},
+	}
+
+	for _, tt := range tests {
+		var (
+			l       = lexer.New(tt.input)
+			p       = New(l)
+			program = p.ParseProgram()
+		)
+
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+		}
+
+		if !testIdentifier(t, slice.Left, "array") {
+			return
+		}
+
+		for _, part := range []struct {
+			exp      ast.Expression
+			expected interface{}
+		}{
+			{slice.Start, tt.start},
+			{slice.Stop, tt.stop},
+			{slice.Step, tt.step},
+		} {
+			if part.expected == nil {
+				if part.exp != nil {
+					t.Errorf("expected omitted slice bound in %q. got=%s", tt.input, part.exp)
+				}
+				continue
+			}
+			testLiteralExpression(t, part.exp, part.expected)
+		}
+	}
+}
+
+func TestParsingHashLiteral(t *testing.T) {
+	input := `{"one": 1, two: 2, 3: 0 + 3, true: 4}`
+
+	var (
+		l       = lexer.New(input)
+		p       = New(l)
+		program = p.ParseProgram()
+	)
+
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 4 {
+		t.Fatalf("len(hash.Pairs) not 4. got=%d", len(hash.Pairs))
+	}
+
+	for i, expected := range []string{"one", "two"} {
+		key, ok := hash.Pairs[i].Key.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("hash.Pairs[%d].Key not *ast.StringLiteral. got=%T", i, hash.Pairs[i].Key)
+		}
+		if key.Value != expected {
+			t.Errorf("key.Value not %q. got=%q", expected, key.Value)
+		}
+		testIntegerLiteral(t, hash.Pairs[i].Value, int64(i+1))
+	}
+
+	testIntegerLiteral(t, hash.Pairs[2].Key, 3)
+	testInfixExpression(t, hash.Pairs[2].Value, 0, "+", 3)
+	testBooleanLiteral(t, hash.Pairs[3].Key, true)
+	testIntegerLiteral(t, hash.Pairs[3].Value, 4)
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := `{}`
+
+	var (
+		l       = lexer.New(input)
+		p       = New(l)
+		program = p.ParseProgram()
+	)
+
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("len(hash.Pairs) not 0. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingMemberExpression(t *testing.T) {
+	input := `user.name`
+
+	var (
+		l       = lexer.New(input)
+		p       = New(l)
+		program = p.ParseProgram()
+	)
+
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	member, ok := stmt.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MemberExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, member.Object, "user") {
+		return
+	}
+
+	if !testIdentifier(t, member.Property, "name") {
+		return
+	}
+}
+
+func TestParsingMemberExpressionErrors(t *testing.T) {
+	tests := []string{
+		"user.",
+		"user.5",
+		"user.(name)",
+	}
+
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "add(1, x[2])\nfn(a) { a }"
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		node     ast.Node
+		pos, end string
+	}{
+		{program, "1:1", "2:12"},
+		{program.Statements[0].(*ast.ExpressionStatement).Expression, "1:1", "1:13"},
+		{program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[1], "1:8", "1:12"},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression, "2:1", "2:12"},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral).Body, "2:7", "2:12"},
+	}
+
+	for i, tt := range tests {
+		if pos := tt.node.Pos().String(); pos != tt.pos {
+			t.Errorf("tests[%d] - pos wrong. expected=%s, got=%s", i, tt.pos, pos)
+		}
+		if end := tt.node.End().String(); end != tt.end {
+			t.Errorf("tests[%d] - end wrong. expected=%s, got=%s", i, tt.end, end)
+		}
+	}
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x := 5\ny := )", "2:6: no prefix parse function for ) found"},
+		{"if (x {\n}", "1:7: expected next token to be ), received {"},
+		{"foo(1, 2", "1:9: expected next token to be ), received newline"},
+		{"foo(1, 2;", "1:9: expected next token to be ), received ;"},
+		{"x := {\n  a: 1,\n  b: 2\n}", "3:7: expected next token to be ,, received newline"},
+		{"x := 1\ny := #", "2:6: illegal character '#'"},
+		{`x := "abc`, "1:6: string literal not terminated"},
+		{"f() += 1", "1:1: can not assign to f()"},
+		{"x := 1\n  a[1:2] = 1", "2:3: can not assign to (a[1:2])"},
+		{"f()++", "1:1: can not assign to f()"},
+		{"fn(a = 1, b) {}", "1:11: parameter b without a default value follows one with a default"},
+		{"fn(a, 1) {}", "1:7: expected next token to be IDENT, received NUMBER"},
+		{"fn(...rest, a) {}", "1:7: rest parameter must be last"},
+		{"fn(a, b, a) {}", "1:10: duplicate parameter a"},
+		{"fn add {}", "1:8: expected next token to be (, received {"},
+		{"fn(...rest = []) {}", "1:12: rest parameter rest can not have a default value"},
+		{"x := 1\nbreak", "2:1: break outside of a loop"},
+		{"if true {\n  continue\n}", "2:3: continue outside of a loop"},
+		{"f := fn() { break }\nf()", "1:13: break outside of a loop"},
+		{"for {\n  fn() {\n    continue\n  }()\n}", "3:5: continue outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestLexerErrorsAreMerged(t *testing.T) {
+	p := New(lexer.New("x := 1 & 2\ny := )"))
+	p.ParseProgram()
+
+	expected := []string{
+		"1:8: illegal character '&'",
+		"2:6: no prefix parse function for ) found",
+	}
+
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)",
+			len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%q", i, msg, errors[i])
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expectedString string
+	}{
+		{
+			"x := ) + )\ny := 2\nz := ]",
+			[]string{
+				"1:6: no prefix parse function for ) found",
+				"3:6: no prefix parse function for ] found",
+			},
+			"(x := <bad expression>; + <bad expression>)y := 2;z := <bad expression>;",
+		},
+		{
+			"if (x { y }\nz := 1",
+			[]string{"1:7: expected next token to be ), received {"},
+			"<bad statement>;z := 1;",
+		},
+		{
+			"if x {\n  y := )\n  z := 2\n}\nfor (a {}\nw := 3",
+			[]string{
+				"2:8: no prefix parse function for ) found",
+				"5:8: expected next token to be ), received {",
+			},
+			"if x { y := <bad expression>;z := 2; }<bad statement>;w := 3;",
+		},
+		{
+			"f := fn() {\n  x := \n}\ny := 1",
+			[]string{"3:1: no prefix parse function for } found"},
+			"f := fn() { x := <bad expression>; };y := 1;",
+		},
+		{
+			"{ a: 1 b: 2 } if x { 1 }",
+			[]string{"1:8: expected next token to be ,, received IDENT"},
+			"<bad expression>if x { 1 }",
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("wrong number of errors for %q. expected=%d, got=%d (%q)",
+				tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+		for i, msg := range tt.expectedErrors {
+			if errors[i] != msg {
+				t.Errorf("errors[%d] wrong for %q. expected=%q, got=%q", i, tt.input, msg, errors[i])
+			}
+		}
+
+		if program.String() != tt.expectedString {
+			t.Errorf("wrong program for %q. expected=%q, got=%q",
+				tt.input, tt.expectedString, program.String())
+		}
+	}
+}
+
 func TestIfExpression(t *testing.T) {
 	input := `if x < y { x; }`
 
@@ -381,6 +981,136 @@ func TestIfElseIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input     string
+		init      string
+		condition string
+		post      string
+	}{
+		{"for { x; }", "", "", ""},
+		{"for x < y { x; }", "", "(x < y)", ""},
+		{"for i := 0; i < 10; i = i + 1 { x; }", "i := 0;", "(i < 10)", "i = (i + 1)"},
+		{"for i := 0; i < 10; i++ { x; }", "i := 0;", "(i < 10)", "i++"},
+		{"for i := 10; i > 0; i -= 2 { x; }", "i := 10;", "(i > 0)", "i -= 2"},
+		{"for ; i < 10; { x; }", "", "(i < 10)", ""},
+		{"for ;; { x; }", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		var (
+			l       = lexer.New(tt.input)
+			p       = New(l)
+			program = p.ParseProgram()
+		)
+
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		for _, part := range []struct {
+			name     string
+			exp      ast.Node
+			expected string
+		}{
+			{"Init", stmt.Init, tt.init},
+			{"Condition", stmt.Condition, tt.condition},
+			{"Post", stmt.Post, tt.post},
+		} {
+			actual := ""
+			if part.exp != nil {
+				actual = part.exp.String()
+			}
+			if actual != part.expected {
+				t.Errorf("stmt.%s wrong for %q. expected=%q, got=%q", part.name, tt.input, part.expected, actual)
+			}
+		}
+
+		if len(stmt.Body.Statements) != 1 {
+			t.Errorf("body is not 1 statements. got=%d\n", len(stmt.Body.Statements))
+		}
+
+		reparsed := New(lexer.New(stmt.String()))
+		again := reparsed.ParseProgram()
+		checkParserErrors(t, reparsed)
+		if again.String() != stmt.String() {
+			t.Errorf("stmt.String() does not round trip for %q. expected=%q, got=%q", tt.input, stmt.String(), again.String())
+		}
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		key      string
+		value    string
+		iterable string
+	}{
+		{"for v in arr { v; }", "", "v", "arr"},
+		{"for i, v in [1, 2] { v; }", "i", "v", "[1, 2]"},
+		{"for k, v in {a: 1} { v; }", "k", "v", `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		var (
+			l       = lexer.New(tt.input)
+			p       = New(l)
+			program = p.ParseProgram()
+		)
+
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T", program.Statements[0])
+		}
+
+		if tt.key == "" && stmt.Key != nil {
+			t.Errorf("stmt.Key was not nil. got=%s", stmt.Key)
+		}
+		if tt.key != "" && !testIdentifier(t, stmt.Key, tt.key) {
+			return
+		}
+		if !testIdentifier(t, stmt.Value, tt.value) {
+			return
+		}
+		if stmt.Iterable.String() != tt.iterable {
+			t.Errorf("stmt.Iterable wrong. expected=%q, got=%q", tt.iterable, stmt.Iterable.String())
+		}
+	}
+}
+
+func TestBreakContinueStatements(t *testing.T) {
+	input := `for { break; continue; }`
+
+	var (
+		l       = lexer.New(input)
+		p       = New(l)
+		program = p.ParseProgram()
+	)
+
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ForStatement)
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body is not 2 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+
+	if _, ok := stmt.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[0] is not ast.BreakStatement. got=%T", stmt.Body.Statements[0])
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[1] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[1])
+	}
+}
+
 func TestNumberLiteralExpression(t *testing.T) {
 	input := `5.4;`
 
@@ -413,6 +1143,86 @@ func TestNumberLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNumberLiteralForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0xff", "255"},
+		{"0XFF", "255"},
+		{"0b1010", "10"},
+		{"0o755", "493"},
+		{"0755", "755"},
+		{"1_000_000", "1000000"},
+		{"0x_dead_beef", "3735928559"},
+		{"0xffffffffffffffffff", "4722366482869645213695"},
+		{"1e9", 1e9},
+		{"2.5e-3", 0.0025},
+		{"1E+2", 100.0},
+		{"1_000.5", 1000.5},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+		switch expected := tt.expected.(type) {
+		case string:
+			lit, ok := exp.(*ast.IntegerLiteral)
+			if !ok {
+				t.Errorf("exp not *ast.IntegerLiteral. got=%T", exp)
+				continue
+			}
+			if lit.Value.String() != expected {
+				t.Errorf("lit.Value wrong for %s. expected=%s, got=%s", tt.input, expected, lit.Value)
+			}
+		case float64:
+			lit, ok := exp.(*ast.NumberLiteral)
+			if !ok {
+				t.Errorf("exp not *ast.NumberLiteral. got=%T", exp)
+				continue
+			}
+			if lit.Value != expected {
+				t.Errorf("lit.Value wrong for %s. expected=%v, got=%v", tt.input, expected, lit.Value)
+			}
+		}
+
+		if exp.String() != tt.input {
+			t.Errorf("literal text lost. expected=%s, got=%s", tt.input, exp.String())
+		}
+	}
+}
+
+func TestMalformedNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0x", "1:1: hexadecimal literal has no digits"},
+		{"0b102", "1:5: invalid digit '2' in binary literal"},
+		{"0o8", "1:3: invalid digit '8' in octal literal"},
+		{"1e", "1:1: exponent has no digits"},
+		{"1_000_", "1:1: '_' must separate successive digits"},
+		{"1__0", "1:1: '_' must separate successive digits"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("wrong number of errors for %q. expected=1, got=%d (%q)", tt.input, len(errors), errors)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input       string
@@ -551,6 +1361,13 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"{a: 1 + 2, b: c}[d]", `({"a": (1 + 2), "b": c}[d])`},
+		{"a[1:b + 2]", "(a[1:(b + 2)])"},
+		{"a[::-1][0]", "((a[::(-1)])[0])"},
+		{"a.b.c", "((a.b).c)"},
+		{"-a.b * c", "((-(a.b)) * c)"},
+		{"a.b(c).d[e]", "(((a.b)(c).d)[e])"},
+		{"user.greet(a + b)", "(user.greet)((a + b))"},
 	}
 
 	for _, tt := range tests {
@@ -595,8 +1412,8 @@ func TestFunctionLiteralParsing(t *testing.T) {
 		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
 	}
 
-	testLiteralExpression(t, function.Parameters[0], "x")
-	testLiteralExpression(t, function.Parameters[1], "y")
+	testLiteralExpression(t, function.Parameters[0].Name, "x")
+	testLiteralExpression(t, function.Parameters[1].Name, "y")
 
 	if len(function.Body.Statements) != 1 {
 		t.Fatalf("function.Body.Statements has more than 1 statement. got=%d\n", len(function.Body.Statements))
@@ -610,6 +1427,37 @@ func TestFunctionLiteralParsing(t *testing.T) {
 	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
 
+func TestFunctionStatementParsing(t *testing.T) {
+	input := `fn add(x, y = 1) { x + y; }
+fn(x) { x }(1)`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Name, "add") {
+		return
+	}
+	if len(stmt.Function.Parameters) != 2 {
+		t.Fatalf("wrong number of parameters. got=%d", len(stmt.Function.Parameters))
+	}
+	if s := stmt.String(); s != "fn add(x, y = 1) { (x + y) }" {
+		t.Errorf("stmt.String() wrong. got=%q", s)
+	}
+
+	if _, ok := program.Statements[1].(*ast.ExpressionStatement); !ok {
+		t.Errorf("program.Statements[1] is not ast.ExpressionStatement. got=%T", program.Statements[1])
+	}
+}
+
 func TestFunctionParameterParsing(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -618,6 +1466,7 @@ func TestFunctionParameterParsing(t *testing.T) {
 		{input: "fn() {};", expectedParams: []string{}},
 		{input: "fn(x) {};", expectedParams: []string{"x"}},
 		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+		{input: "fn(\n  x,\n  y,\n) {}", expectedParams: []string{"x", "y"}},
 	}
 
 	for _, tt := range tests {
@@ -637,7 +1486,38 @@ func TestFunctionParameterParsing(t *testing.T) {
 		}
 
 		for i, ident := range tt.expectedParams {
-			testLiteralExpression(t, function.Parameters[i], ident)
+			testLiteralExpression(t, function.Parameters[i].Name, ident)
+		}
+	}
+}
+
+func TestDefaultParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn(a = 1) {}", []string{"a = 1"}},
+		{"fn(a, b = 10, c = a + 1) {}", []string{"a", "b = 10", "c = (a + 1)"}},
+		{"fn(a, b = [1, 2],) {}", []string{"a", "b = [1, 2]"}},
+		{"fn(...rest) {}", []string{"...rest"}},
+		{"fn(a, b = 1, ...rest) {}", []string{"a", "b = 1", "...rest"}},
+		{"fn(\n  a,\n  ...rest,\n) {}", []string{"a", "...rest"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+		if len(function.Parameters) != len(tt.expected) {
+			t.Fatalf("length parameters wrong for %q. want=%d, got=%d",
+				tt.input, len(tt.expected), len(function.Parameters))
+		}
+		for i, expected := range tt.expected {
+			if s := function.Parameters[i].String(); s != expected {
+				t.Errorf("parameter %d wrong for %q. expected=%q, got=%q", i, tt.input, expected, s)
+			}
 		}
 	}
 }
@@ -719,7 +1599,7 @@ func testInfixExpression(t *testing.T, exp ast.Expression, left interface{}, ope
 func testLiteralExpression(t *testing.T, exp ast.Expression, expected interface{}) bool {
 	switch v := expected.(type) {
 	case int:
-		return testNumberLiteral(t, exp, float64(v))
+		return testIntegerLiteral(t, exp, int64(v))
 	case float64:
 		return testNumberLiteral(t, exp, v)
 	case string:
@@ -753,6 +1633,25 @@ func testNumberLiteral(t *testing.T, nl ast.Expression, value float64) bool {
 	return true
 }
 
+func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
+	integer, ok := il.(*ast.IntegerLiteral)
+	if !ok {
+		t.Errorf("il not *ast.IntegerLiteral. got=%T", il)
+		return false
+	}
+
+	if !integer.Value.IsInt64() || integer.Value.Int64() != value {
+		t.Errorf("integer.Value not %d. got=%s", value, integer.Value)
+		return false
+	}
+
+	if integer.TokenLiteral() != fmt.Sprintf("%d", value) {
+		t.Errorf("integer.TokenLiteral not %d. got=%s", value, integer.TokenLiteral())
+		return false
+	}
+	return true
+}
+
 func testIdentifier(t *testing.T, exp ast.Expression, value string) bool {
 	ident, ok := exp.(*ast.Identifier)
 	if !ok {
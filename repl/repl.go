@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"dara/evaluator"
 	"dara/lexer"
+	"dara/lint"
 	"dara/parser"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"strings"
 )
 
 const PROMPT = "→ "
@@ -16,19 +19,24 @@ func Start(in io.Reader, out io.Writer) {
 	var (
 		scanner = bufio.NewScanner(in)
 		env     = evaluator.NewEnvironment()
+		// Every line is lexed as its own file, so errors raised later by
+		// functions declared on an earlier line can still show their source.
+		sources = make(map[string]string)
 	)
 
-	for {
+	for n := 1; ; n++ {
 		fmt.Print(PROMPT)
 		if scanned := scanner.Scan(); !scanned {
 			return
 		}
 
 		var (
-			line = scanner.Text()
-			l    = lexer.New(line)
-			p    = parser.New(l)
+			line     = scanner.Text()
+			filename = fmt.Sprintf("<repl %d>", n)
+			l        = lexer.NewFile(filename, line)
+			p        = parser.New(l)
 		)
+		sources[filename] = line
 
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
@@ -37,25 +45,107 @@ func Start(in io.Reader, out io.Writer) {
 		}
 
 		evaluated := evaluator.Eval(program, env)
+		if err, ok := evaluated.(*evaluator.Error); ok {
+			writeString(out, FormatError(err, sources[err.Pos.Filename]))
+			continue
+		}
 		if evaluated != nil {
-			if _, err := io.WriteString(out, evaluated.Inspect()); err != nil {
-				log.Fatalln(err)
-			}
+			writeString(out, evaluated.Inspect()+"\n")
+		}
+	}
+}
+
+// Options controls the optional checks RunFile makes before running a program.
+type Options struct {
+	// WarnShadowing reports declarations that shadow a name declared in an
+	// enclosing scope.
+	WarnShadowing bool
+}
+
+// RunFile evaluates the program in filename, printing any errors and warnings
+// to out. It reports whether the program ran without errors.
+func RunFile(filename string, out io.Writer, opts Options) bool {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		writeString(out, err.Error()+"\n")
+		return false
+	}
 
-			if _, err := io.WriteString(out, "\n"); err != nil {
-				log.Fatalln(err)
-			}
+	source := string(data)
+	p := parser.New(lexer.NewFile(filename, source))
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors())
+		return false
+	}
+
+	if opts.WarnShadowing {
+		for _, warning := range lint.Shadowing(program) {
+			writeString(out, "warning: "+warning.String()+"\n")
 		}
 	}
+
+	evaluated := evaluator.Eval(program, evaluator.NewEnvironment())
+	if err, ok := evaluated.(*evaluator.Error); ok {
+		writeString(out, FormatError(err, source))
+		return false
+	}
+	return true
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	if _, err := io.WriteString(out, "  parser errors:\n"); err != nil {
-		log.Fatalln(err)
+// FormatError formats a runtime error as `file:line:col: message`, followed by
+// the offending line of source with a caret under the error's column and the
+// function calls that led to it.
+func FormatError(err *evaluator.Error, source string) string {
+	var out strings.Builder
+
+	out.WriteString(err.Error())
+	out.WriteString("\n")
+	writeSnippet(&out, err, source)
+	for _, frame := range err.Stack {
+		out.WriteString("\t" + frame.String() + "\n")
 	}
-	for _, msg := range errors {
-		if _, err := io.WriteString(out, "\t"+msg+"\n"); err != nil {
-			log.Fatalln(err)
+
+	return out.String()
+}
+
+func writeSnippet(out *strings.Builder, err *evaluator.Error, source string) {
+	lines := strings.Split(source, "\n")
+	if !err.Pos.IsValid() || err.Pos.Line > len(lines) {
+		return
+	}
+
+	line := strings.TrimRight(lines[err.Pos.Line-1], "\r")
+	column := err.Pos.Column - 1
+	if column > len(line) {
+		column = len(line)
+	}
+
+	// Columns count bytes, so the caret is indented by one space for each
+	// character before it. Tabs are kept so it lines up with the snippet.
+	var indent strings.Builder
+	for _, ch := range line[:column] {
+		if ch == '\t' {
+			indent.WriteRune(ch)
+		} else {
+			indent.WriteRune(' ')
 		}
 	}
+
+	out.WriteString("\t" + line + "\n")
+	out.WriteString("\t" + indent.String() + "^\n")
+}
+
+func printParserErrors(out io.Writer, errors []string) {
+	writeString(out, "  parser errors:\n")
+	for _, msg := range errors {
+		writeString(out, "\t"+msg+"\n")
+	}
+}
+
+func writeString(out io.Writer, s string) {
+	if _, err := io.WriteString(out, s); err != nil {
+		log.Fatalln(err)
+	}
 }
@@ -0,0 +1,107 @@
+package repl
+
+import (
+	"dara/evaluator"
+	"dara/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatError(t *testing.T) {
+	at := func(line, column int) token.Position {
+		return token.Position{Filename: "test.dr", Line: line, Column: column}
+	}
+
+	tests := []struct {
+		source   string
+		err      *evaluator.Error
+		expected string
+	}{
+		{
+			"x := 1\ny := x + true",
+			&evaluator.Error{Message: "type mismatch", Pos: at(2, 6)},
+			"test.dr:2:6: type mismatch\n\ty := x + true\n\t     ^\n",
+		},
+		{
+			"f := fn() {\n\t\treturn 1 + nil\n}",
+			&evaluator.Error{Message: "type mismatch", Pos: at(2, 10)},
+			"test.dr:2:10: type mismatch\n\t\t\treturn 1 + nil\n\t\t\t       ^\n",
+		},
+		{
+			// é and 日 are several bytes long, but take up one column each.
+			`café := "日本" + 1`,
+			&evaluator.Error{Message: "type mismatch", Pos: at(1, 10)},
+			"test.dr:1:10: type mismatch\n\tcafé := \"日本\" + 1\n\t        ^\n",
+		},
+		{
+			"\tcafé := 日 + 1",
+			&evaluator.Error{Message: "undeclared name: 日", Pos: at(1, 11)},
+			"test.dr:1:11: undeclared name: 日\n\t\tcafé := 日 + 1\n\t\t        ^\n",
+		},
+		{
+			"x := 1\r\ny := -true\r\n",
+			&evaluator.Error{Message: "invalid operation", Pos: at(2, 6)},
+			"test.dr:2:6: invalid operation\n\ty := -true\n\t     ^\n",
+		},
+		{
+			"add(1)",
+			&evaluator.Error{Message: "no such function", Pos: at(1, 20)},
+			"test.dr:1:20: no such function\n\tadd(1)\n\t      ^\n",
+		},
+		{
+			"x := 1",
+			&evaluator.Error{Message: "out of the source", Pos: at(3, 1)},
+			"test.dr:3:1: out of the source\n",
+		},
+		{
+			"x := 1",
+			&evaluator.Error{Message: "no position"},
+			"no position\n",
+		},
+		{
+			"f := fn() { nil.a }\nf()",
+			&evaluator.Error{
+				Message: "no member a",
+				Pos:     at(1, 13),
+				Stack:   []evaluator.Frame{{Function: "f", Pos: at(2, 1)}},
+			},
+			"test.dr:1:13: no member a\n\tf := fn() { nil.a }\n\t            ^\n\tf called at test.dr:2:1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if formatted := FormatError(tt.err, tt.source); formatted != tt.expected {
+			t.Errorf("wrong error for %q.\nexpected=%q\ngot=     %q", tt.source, tt.expected, formatted)
+		}
+	}
+}
+
+func TestRunFileReportsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dara")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "script.dr")
+	source := "add := fn(a, b) {\n\treturn a + b\n}\nnaïve := \"é\"\nadd(naïve, 1)\n"
+	if err := ioutil.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if RunFile(filename, &out, Options{}) {
+		t.Fatalf("RunFile reported success for a failing program")
+	}
+
+	expected := filename + ":2:9: type mismatch: string + integer\n" +
+		"\t\treturn a + b\n" +
+		"\t\t       ^\n" +
+		"\tadd called at " + filename + ":5:1\n"
+	if out.String() != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, out.String())
+	}
+}
@@ -1,14 +1,46 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first character of the token
+	End     Position // position immediately after the token
 }
 
 func New(tokenType TokenType, literal string) *Token {
-	return &Token{tokenType, literal}
+	return &Token{Type: tokenType, Literal: literal}
+}
+
+// Position describes a location in a source file. Lines and columns start at
+// 1, and columns are counted in bytes.
+type Position struct {
+	Filename string
+	Offset   int // byte offset, starting at 0
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position has been set.
+func (pos Position) IsValid() bool { return pos.Line > 0 }
+
+// String returns the position as `file:line:column`, leaving out the filename
+// if there isn't one. An invalid position is returned as "-".
+func (pos Position) String() string {
+	s := pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
 }
 
 const (
@@ -21,6 +53,11 @@ const (
 	NUMBER TokenType = "NUMBER"
 	STRING TokenType = "STRING"
 
+	// Parts of an interpolated string: "head ${a} middle ${b} tail".
+	TEMPLATE_HEAD   TokenType = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE TokenType = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   TokenType = "TEMPLATE_TAIL"
+
 	// Operators.
 	ASSIGN   TokenType = "="
 	PLUS     TokenType = "+"
@@ -39,10 +76,21 @@ const (
 	AND     TokenType = "&&"
 	OR      TokenType = "||"
 	DECLARE TokenType = ":="
+	SPREAD  TokenType = "..."
+
+	PLUS_ASSIGN     TokenType = "+="
+	MINUS_ASSIGN    TokenType = "-="
+	ASTERISK_ASSIGN TokenType = "*="
+	SLASH_ASSIGN    TokenType = "/="
+	MOD_ASSIGN      TokenType = "%="
+	INC             TokenType = "++"
+	DEC             TokenType = "--"
 
 	// Delimiters.
 	COMMA     TokenType = ","
 	SEMICOLON TokenType = ";"
+	COLON     TokenType = ":"
+	DOT       TokenType = "."
 
 	LPAREN   TokenType = "("
 	RPAREN   TokenType = ")"
@@ -59,16 +107,26 @@ const (
 	ELSE     TokenType = "else"
 	RETURN   TokenType = "return"
 	NIL      TokenType = "nil"
+	FOR      TokenType = "for"
+	IN       TokenType = "in"
+	BREAK    TokenType = "break"
+	CONTINUE TokenType = "continue"
+	CONST    TokenType = "const"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"nil":    NIL,
+	"fn":       FUNCTION,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"nil":      NIL,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"const":    CONST,
 }
 
 func LookupIdent(ident string) TokenType {